@@ -3,6 +3,7 @@ package app
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -11,6 +12,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/johannes-kuhfuss/calcmsfeeder/config"
@@ -18,40 +21,123 @@ import (
 	"github.com/johannes-kuhfuss/calcmsfeeder/service"
 )
 
-const dateFormat = "2006-01-02"
+const (
+	dateFormat      = "2006-01-02"
+	eventTimeFormat = "2006-01-02 15:04 MST"
+)
 
 // Runner owns the mutable state and dependencies for one application run.
 type Runner struct {
 	Cfg       config.AppConfig
 	Plan      domain.ExecutionPlan
 	Service   service.CalCmsService
+	Events    service.EventSource
 	Input     *bufio.Scanner
 	Output    io.Writer
 	Now       func() time.Time
 	Overwrite bool
+	ICSFile   string
+	// KeepGoing continues with the remaining events after an event fails.
+	KeepGoing bool
+	// OutputFormat selects the format of the plan preview and the run report.
+	OutputFormat string
+	// Report receives the run report; it defaults to Output.
+	Report io.Writer
+	// RunID identifies the run in the audit log; Run sets it if it is empty.
+	RunID         string
+	progress      *progressReporter
+	audit         *service.AuditLog
+	checksums     map[string]string
+	operatingUser string
+	machine       string
+	rangeSet      bool
+	// otherSeriesKeys collects the keys of queried events that belong to no configured series.
+	otherSeriesKeys map[string]bool
+	otherKeysMu     sync.Mutex
+	// stopRequested ends an upload or rollback before its next event; changing is set while one runs.
+	stopRequested atomic.Bool
+	changing      atomic.Bool
 }
 
-// RunApp parses command-line options, loads configuration, and runs the CLI.
+// RunApp dispatches to a subcommand or runs the interactive upload workflow.
 func RunApp() error {
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case generateStreamsCommand:
+			return runGenerateStreams(args[1:], os.Stdout)
+		case auditCommand:
+			return runAudit(args[1:], os.Stdout)
+		case rollbackCommand:
+			return runRollback(args[1:])
+		case checkCommand:
+			return runCheck(args[1:])
+		case discoverCommand:
+			return runDiscover(args[1:])
+		}
+	}
+	return runUpload(args)
+}
+
+// runUpload parses command-line options, loads configuration, and runs the upload workflow.
+func runUpload(args []string) error {
 	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 	flags.SetOutput(os.Stderr)
 	envFile := flags.String("config.file", ".env", "Specify location of config file. Default is .env")
 	overwrite := flags.Bool("overwrite", false, "Replace an active recording when an upload is already present")
-	if err := flags.Parse(os.Args[1:]); err != nil {
+	keepGoing := flags.Bool("keep-going", false, "Continue with the remaining events when an event fails and summarize the failures at the end")
+	icsFile := flags.String("ics", "", "Write the planned events and their upload results to this iCalendar file")
+	start := flags.String("start", "", "Process events from this date, date-time (YYYY-MM-DDTHH:MM) or range such as tomorrow, +3d, 2026-W43 or 2026-11-01..2026-11-30 without asking")
+	end := flags.String("end", "", "Process events until the end of this date, such as 2026-11-30 or next friday, or until this date-time (exclusive)")
+	days := flags.Int("days", 0, "Process this many days from -start instead of -end")
+	outputFormat := flags.String("output", OutputText, "Format of the plan preview and the run report: "+strings.Join(outputFormats, ", "))
+	timeWindow := flags.String("time-window", "", "Only process events starting within this daily window, e.g. 06:00-10:00")
+	if err := flags.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return nil
 		}
-		return err
+		return usageError(err)
 	}
 
 	var cfg config.AppConfig
 	if err := config.InitConfig(*envFile, &cfg); err != nil {
 		return err
 	}
+	if err := validateOutputFormat(*outputFormat); err != nil {
+		return usageError(err)
+	}
 	runner := NewRunner(cfg, os.Stdin, os.Stdout, time.Now)
+	runner.OutputFormat = *outputFormat
+	if *outputFormat != OutputText {
+		// Keep standard output a clean document for tools; prompts and progress go to standard error.
+		runner.Output = os.Stderr
+		runner.Report = os.Stdout
+	}
 	runner.Overwrite = *overwrite
-	runner.Service = service.NewCalCmsService(&runner.Cfg)
-	return runner.Run()
+	runner.KeepGoing = *keepGoing
+	runner.ICSFile = *icsFile
+	if *start != "" {
+		if err := runner.SetRange(*start, *end, *days); err != nil {
+			return usageError(err)
+		}
+	} else if *end != "" || *days != 0 {
+		return usageError(fmt.Errorf("-end and -days require -start"))
+	}
+	if *timeWindow != "" {
+		if err := runner.SetDailyWindow(*timeWindow); err != nil {
+			return usageError(err)
+		}
+	}
+	svc := service.NewCalCmsService(&runner.Cfg)
+	svc.Progress = runner.ReportProgress
+	svc.KeepEvent = runner.IsConfiguredSeries
+	runner.Service = svc
+	events, err := service.NewEventSource(&runner.Cfg, svc)
+	if err != nil {
+		return err
+	}
+	runner.Events = events
+	return withInterrupts(runner, runner.Run)
 }
 
 // NewRunner constructs a runner with a single shared input scanner.
@@ -59,63 +145,94 @@ func NewRunner(cfg config.AppConfig, input io.Reader, output io.Writer, now func
 	if now == nil {
 		now = time.Now
 	}
-	plan := domain.ExecutionPlan{Series: make(map[string]domain.SeriesPlan, len(cfg.Series))}
+	plan := domain.ExecutionPlan{Series: make(map[string]domain.SeriesPlan, len(cfg.Series)), Results: make(map[int]domain.EventResult)}
 	for key, series := range cfg.Series {
 		plan.Series[key] = domain.SeriesPlan{SeriesInfo: series}
 	}
+	machine, _ := os.Hostname()
 	return &Runner{
-		Cfg:    cfg,
-		Plan:   plan,
-		Input:  bufio.NewScanner(input),
-		Output: output,
-		Now:    now,
+		Cfg:           cfg,
+		Plan:          plan,
+		Input:         bufio.NewScanner(input),
+		Output:        output,
+		Now:           now,
+		OutputFormat:  OutputText,
+		operatingUser: operatingUser(),
+		machine:       machine,
 	}
 }
 
 // Run executes the interactive application workflow.
-func (r *Runner) Run() error {
+func (r *Runner) Run(ctx context.Context) error {
 	if r.Service == nil {
 		return fmt.Errorf("calCMS service is nil")
 	}
-	if err := r.getUserInput(); err != nil {
+	if r.RunID == "" {
+		r.RunID = newRunID(r.Now())
+	}
+	for _, warning := range r.Cfg.Warnings {
+		fmt.Fprintf(r.Output, "Warning: %v\r\n", warning)
+	}
+	if err := r.getUserInput(ctx); err != nil {
 		return err
 	}
-	if err := r.queryCalCMSEvents(); err != nil {
+	if err := r.queryCalCMSEvents(ctx); err != nil {
 		return err
 	}
-	confirmed, err := r.showStatusAndConfirm()
+	confirmed, err := r.showStatusAndConfirm(ctx)
 	if err != nil {
 		return err
 	}
-	if confirmed {
-		return r.uploadFilesToCalCMS()
+	if !confirmed {
+		return nil
 	}
-	return nil
+	err = r.uploadFilesToCalCMS(ctx)
+	return errors.Join(err, r.writeRunReport(), r.exportICalendar())
 }
 
-func (r *Runner) getUserInput() error {
-	if err := r.readStartDate(); err != nil {
+func (r *Runner) getUserInput(ctx context.Context) error {
+	if r.rangeSet {
+		return nil
+	}
+	if err := r.readStartDate(ctx); err != nil {
 		return err
 	}
-	return r.readDuration()
+	if r.Plan.EndDate.IsZero() {
+		if err := r.readDuration(ctx); err != nil {
+			return err
+		}
+	}
+	r.setDayRange(r.Plan.StartDate, r.Plan.EndDate)
+	return nil
 }
 
-func (r *Runner) readLine(context string) (string, error) {
-	if !r.Input.Scan() {
-		if err := r.Input.Err(); err != nil {
-			return "", fmt.Errorf("%s: %w", context, err)
+// readLine reads one answer. It returns early when ctx is cancelled; the pending read is then
+// abandoned, so the runner must not read input after a cancellation.
+func (r *Runner) readLine(ctx context.Context, purpose string) (string, error) {
+	scanned := make(chan bool, 1)
+	go func() { scanned <- r.Input.Scan() }()
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("%s: %w", purpose, ctx.Err())
+	case ok := <-scanned:
+		if !ok {
+			if err := r.Input.Err(); err != nil {
+				return "", fmt.Errorf("%s: %w", purpose, err)
+			}
+			return "", fmt.Errorf("%s: input closed", purpose)
 		}
-		return "", fmt.Errorf("%s: input closed", context)
 	}
 	return r.Input.Text(), nil
 }
 
-func (r *Runner) readStartDate() error {
-	now := r.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+// readStartDate asks for the start day. If the answer names several days, such as a week,
+// a month or FIRST..LAST, it also sets the end date and no duration is asked for.
+func (r *Runner) readStartDate(ctx context.Context) error {
+	today := r.today()
+	r.Plan.EndDate = time.Time{}
 	for {
-		fmt.Fprint(r.Output, "Enter start date as YYYY-MM-DD (or leave empty for today): ")
-		startDate, err := r.readLine("read start date")
+		fmt.Fprint(r.Output, "Enter start date or range (YYYY-MM-DD, tomorrow, next monday, +3d, 2026-W43, 2026-11-01..2026-11-30, or leave empty for today): ")
+		startDate, err := r.readLine(ctx, "read start date")
 		if err != nil {
 			return err
 		}
@@ -123,24 +240,39 @@ func (r *Runner) readStartDate() error {
 			r.Plan.StartDate = today
 			return nil
 		}
-		d, err := time.ParseInLocation(dateFormat, startDate, today.Location())
+		days, err := parseDateExpression(startDate, today)
 		if err != nil {
-			fmt.Fprintln(r.Output, "Start Date must be entered as YYYY-MM-DD.")
+			fmt.Fprintf(r.Output, "Start Date must be one of %s.\r\n", dateExpressionHelp)
 			continue
 		}
-		if d.Before(today) {
+		isRange := !days.isSingleDay()
+		if isRange {
+			var ok bool
+			if days, ok = days.fromDay(today); !ok {
+				fmt.Fprintln(r.Output, "Range must not lie in the past")
+				continue
+			}
+		}
+		if days.First.Before(today) {
 			fmt.Fprintln(r.Output, "Start Date must be today or later")
 			continue
 		}
-		r.Plan.StartDate = d
+		if isRange && days.days() > r.Cfg.CalCms.MaxDurationInDays {
+			fmt.Fprintf(r.Output, "Range covers %v days, the maximum is %v.\r\n", days.days(), r.Cfg.CalCms.MaxDurationInDays)
+			continue
+		}
+		r.Plan.StartDate = days.First
+		if isRange {
+			r.Plan.EndDate = days.Last
+		}
 		return nil
 	}
 }
 
-func (r *Runner) readDuration() error {
+func (r *Runner) readDuration(ctx context.Context) error {
 	for {
 		fmt.Fprintf(r.Output, "Enter processing duration in days (1 .. %v, or leave empty for default = %v): ", r.Cfg.CalCms.MaxDurationInDays, r.Cfg.CalCms.DefaultDurationInDays)
-		duration, err := r.readLine("read duration")
+		duration, err := r.readLine(ctx, "read duration")
 		if err != nil {
 			return err
 		}
@@ -166,76 +298,255 @@ func endDateForDuration(start time.Time, days int) time.Time {
 	return start.AddDate(0, 0, days-1)
 }
 
-func (r *Runner) showStatusAndConfirm() (bool, error) {
-	fmt.Fprintf(r.Output, "Using start date %v\r\n", r.Plan.StartDate.Format(dateFormat))
-	fmt.Fprintf(r.Output, "Using end date %v\r\n", r.Plan.EndDate.Format(dateFormat))
-	fmt.Fprintf(r.Output, "Overwrite existing recordings: %v\r\n", r.Overwrite)
+// showSeriesSummary lists the matching events per series and any duration mismatches.
+func (r *Runner) showSeriesSummary() {
 	for _, key := range r.sortedSeriesKeys() {
 		data := r.Plan.Series[key]
-		fmt.Fprintf(r.Output, "For \"%v\" found %v entries. Will upload file \"%v\". (IDs: %v)\r\n", key, len(data.EventIDs), data.FileToUpload, data.EventIDs)
+		fmt.Fprintf(r.Output, "For \"%v\" found %v entries. Will upload file \"%v\". (IDs: %v)\r\n", key, len(data.Events), data.FileToUpload, data.EventIDs())
+		for _, event := range data.Events {
+			if scheduled, mismatch := r.durationMismatch(data, event); mismatch {
+				fmt.Fprintf(r.Output, "  Event %d (%v): file plays %v, event is scheduled for %v.\r\n", event.EventID, r.formatEventStart(event), data.AudioDuration, scheduled)
+			}
+		}
+	}
+}
+
+func (r *Runner) showStatusAndConfirm(ctx context.Context) (bool, error) {
+	if isWholeDay(r.Plan.From) && isWholeDay(r.Plan.Till) {
+		fmt.Fprintf(r.Output, "Using start date %v\r\n", r.Plan.StartDate.Format(dateFormat))
+		fmt.Fprintf(r.Output, "Using end date %v\r\n", r.Plan.EndDate.Format(dateFormat))
+	} else {
+		fmt.Fprintf(r.Output, "Using start %v\r\n", r.Plan.From.Format(eventTimeFormat))
+		fmt.Fprintf(r.Output, "Using end %v (exclusive)\r\n", r.Plan.Till.Format(eventTimeFormat))
+	}
+	if !r.Plan.DailyWindow.IsZero() {
+		fmt.Fprintf(r.Output, "Only events starting between %v and %v\r\n", formatTimeOfDay(r.Plan.DailyWindow.From), formatTimeOfDay(r.Plan.DailyWindow.Till))
 	}
-	fmt.Fprint(r.Output, "Confirm with \"y\" to continue: ")
-	decision, err := r.readLine("read confirmation")
+	fmt.Fprintf(r.Output, "Overwrite existing recordings: %v\r\n", r.Overwrite)
+	if r.OutputFormat != OutputText && r.OutputFormat != "" {
+		if err := writeReport(r.Output, r.OutputFormat, r.buildReport(reportPlan, r.OutputFormat)); err != nil {
+			return false, fmt.Errorf("write plan preview: %w", err)
+		}
+	} else {
+		r.showSeriesSummary()
+	}
+	if r.Cfg.Uploads.DurationMismatch == config.DurationMismatchRefuse {
+		fmt.Fprint(r.Output, "Events with a duration mismatch will be skipped.\r\n")
+	}
+	fmt.Fprint(r.Output, "Confirm with \"y\" to continue or \"s\" to select single events: ")
+	decision, err := r.readLine(ctx, "read confirmation")
 	if err != nil {
 		return false, err
 	}
-	if !strings.EqualFold(strings.TrimSpace(decision), "y") {
-		fmt.Fprint(r.Output, "Aborting...")
+	decision = strings.TrimSpace(decision)
+	if strings.EqualFold(decision, "s") {
+		return r.selectEvents(ctx)
+	}
+	if !strings.EqualFold(decision, "y") {
+		fmt.Fprint(r.Output, "Aborting...\r\n")
 		return false, nil
 	}
 	return true, nil
 }
 
-func (r *Runner) queryCalCMSEvents() error {
-	events, err := r.Service.QueryEvents(r.Plan.StartDate, r.Plan.EndDate)
+func (r *Runner) queryCalCMSEvents(ctx context.Context) error {
+	source := r.Events
+	if source == nil {
+		source = r.Service
+	}
+	r.forgetOtherSeriesKeys()
+	events, err := source.QueryEvents(ctx, r.Plan.From, r.Plan.Till)
 	if err != nil {
-		return fmt.Errorf("query events from calCMS: %w", err)
+		return fmt.Errorf("query events from %v: %w", r.eventSourceName(), err)
 	}
 	for key, entry := range r.Plan.Series {
-		entry.EventIDs = nil
+		entry.Events = nil
 		r.Plan.Series[key] = entry
 	}
+	r.Plan.Results = make(map[int]domain.EventResult)
 	for _, event := range events {
-		if entry, ok := r.Plan.Series[event.Skey]; ok {
-			entry.EventIDs = append(entry.EventIDs, event.EventID)
-			r.Plan.Series[event.Skey] = entry
+		if !r.IsConfiguredSeries(event) || !r.inDailyWindow(event) {
+			continue
 		}
+		entry := r.Plan.Series[event.Skey]
+		entry.Events = append(entry.Events, event)
+		r.Plan.Series[event.Skey] = entry
 	}
+	r.warnSeriesKeyMismatches()
 	return nil
 }
 
-func (r *Runner) uploadFilesToCalCMS() error {
+// IsConfiguredSeries reports whether an event belongs to a configured series and notes the
+// keys of other events for the series key suggestions. It only reads the plan and is safe
+// for concurrent use while events are queried.
+func (r *Runner) IsConfiguredSeries(event domain.CalCMSEvent) bool {
+	_, ok := r.Plan.Series[event.Skey]
+	if !ok {
+		r.noteOtherSeriesKey(event.Skey)
+	}
+	return ok
+}
+
+// inDailyWindow reports whether an event starts within the daily window.
+// Without a window every event matches; with one, events without a readable start are left out.
+func (r *Runner) inDailyWindow(event domain.CalCMSEvent) bool {
+	if r.Plan.DailyWindow.IsZero() {
+		return true
+	}
+	start, err := event.Start(r.Cfg.TimeLocation())
+	return err == nil && r.Plan.DailyWindow.Contains(start)
+}
+
+// eventSourceName describes where the events come from in messages.
+func (r *Runner) eventSourceName() string {
+	if r.Events == nil || r.Cfg.Events.Source == "" || r.Cfg.Events.Source == config.EventSourceCalCms {
+		return "calCMS"
+	}
+	return fmt.Sprintf("%v file %q", r.Cfg.Events.Source, r.Cfg.Events.File)
+}
+
+func (r *Runner) uploadFilesToCalCMS(ctx context.Context) error {
 	if r.eventCount() == 0 {
 		fmt.Fprintln(r.Output, "No matching events; nothing to upload.")
 		return nil
 	}
-	if err := r.Service.Login(r.Cfg.CalCms.CmsUser, r.Cfg.CalCms.CmsPass); err != nil {
+	if err := r.openAudit(); err != nil {
+		return err
+	}
+	defer r.closeAudit()
+	if err := r.Service.Login(ctx, r.Cfg.CalCms.CmsUser, r.Cfg.CalCms.CmsPass); err != nil {
 		return fmt.Errorf("log in to calCMS: %w", err)
 	}
+	r.progress = newProgressReporter(r.Output, r.Now)
+	r.progress.start(r.eventCount(), r.plannedBytes())
+	r.changing.Store(true)
+	defer r.changing.Store(false)
+	var failures []*eventError
+	var stopErr error
+	processed := 0
+series:
 	for _, key := range r.sortedSeriesKeys() {
 		data := r.Plan.Series[key]
-		if len(data.EventIDs) == 0 {
+		if len(data.Events) == 0 {
 			continue
 		}
+		size := fileSize(data.FileToUpload)
 		fmt.Fprintf(r.Output, "Uploading files for \"%v\".\r\n", key)
-		for _, eventID := range data.EventIDs {
-			hasRecording, err := r.Service.HasRecording(eventID, data.SeriesID)
-			if err != nil {
-				return fmt.Errorf("check existing recording for event %d: %w", eventID, err)
+		for _, event := range data.Events {
+			if r.stopping(ctx) {
+				stopErr = r.stopBeforeEvent(event.EventID, processed, r.eventCount())
+				break series
 			}
-			if hasRecording && !r.Overwrite {
-				fmt.Fprintf(r.Output, "Skipping event %d: an active recording is already present (use -overwrite to replace it).\r\n", eventID)
+			processed++
+			err := r.uploadEvent(ctx, key, data, event, size)
+			if err == nil {
 				continue
 			}
-			if hasRecording {
-				fmt.Fprintf(r.Output, "Overwriting active recording for event %d.\r\n", eventID)
-			}
-			if err := r.Service.UploadFile(eventID, data.SeriesID, data.FileToUpload); err != nil {
-				return fmt.Errorf("upload %q for event %d: %w", data.FileToUpload, eventID, err)
+			var failure *eventError
+			if !r.KeepGoing || !errors.As(err, &failure) {
+				if len(failures) > 0 {
+					// The error that stopped the run decides the exit code; earlier failures are only reported.
+					r.showFailureSummary(failures)
+					return errors.Join(err, fmt.Errorf("%d earlier events failed: %v", len(failures), joinEventErrors(failures)))
+				}
+				return err
 			}
+			fmt.Fprintf(r.Output, "Event %d failed, continuing: %v\r\n", event.EventID, err)
+			failures = append(failures, failure)
 		}
 	}
-	return nil
+	if len(failures) > 0 {
+		r.showFailureSummary(failures)
+		return errors.Join(stopErr, fmt.Errorf("%d of %d %w: %w", len(failures), r.eventCount(), ErrEventsFailed, joinEventErrors(failures)))
+	}
+	return stopErr
+}
+
+func joinEventErrors(failures []*eventError) error {
+	errs := make([]error, len(failures))
+	for i, failure := range failures {
+		errs[i] = failure
+	}
+	return errors.Join(errs...)
+}
+
+// uploadEvent checks the existing recording of one event and uploads the series file to it.
+// Failures that only affect this event are returned as *eventError.
+func (r *Runner) uploadEvent(ctx context.Context, key string, data domain.SeriesPlan, event domain.CalCMSEvent, size int64) error {
+	eventID := event.EventID
+	if scheduled, mismatch := r.durationMismatch(data, event); mismatch && r.Cfg.Uploads.DurationMismatch == config.DurationMismatchRefuse {
+		reason := fmt.Sprintf("file plays %v, event is scheduled for %v", data.AudioDuration, scheduled)
+		fmt.Fprintf(r.Output, "Skipping event %d: %v.\r\n", eventID, reason)
+		r.Plan.Results[eventID] = domain.EventResult{Outcome: domain.OutcomeSkipped, Reason: reason, Recording: domain.RecordingUnknown}
+		r.progress.skipEvent(size)
+		return nil
+	}
+	hasRecording, err := r.Service.HasRecording(ctx, eventID, data.SeriesID)
+	if err != nil {
+		r.Plan.Results[eventID] = domain.EventResult{Outcome: domain.OutcomeFailed, Reason: err.Error(), Recording: domain.RecordingUnknown}
+		r.progress.skipEvent(size)
+		return newEventError(key, event, "check recording", fmt.Errorf("check existing recording for event %d: %w", eventID, err))
+	}
+	if hasRecording && !r.Overwrite {
+		fmt.Fprintf(r.Output, "Skipping event %d: an active recording is already present (use -overwrite to replace it).\r\n", eventID)
+		r.Plan.Results[eventID] = domain.NewEventResult(domain.OutcomeSkipped, "active recording present", hasRecording)
+		r.progress.skipEvent(size)
+		return nil
+	}
+	if hasRecording {
+		fmt.Fprintf(r.Output, "Overwriting active recording for event %d.\r\n", eventID)
+	}
+	var paths recordingPaths
+	if r.audit != nil {
+		// Hash before uploading so the audit log names the bytes that were sent.
+		if _, err := r.fileChecksum(data.FileToUpload); err != nil {
+			return err
+		}
+		if hasRecording {
+			paths.previous = r.activeRecordingPath(ctx, eventID, data.SeriesID)
+		}
+	}
+	r.progress.startEvent(eventID)
+	err = r.Service.UploadFile(ctx, eventID, data.SeriesID, data.FileToUpload)
+	r.progress.finishEvent()
+	if err != nil {
+		r.Plan.Results[eventID] = domain.NewEventResult(domain.OutcomeFailed, err.Error(), hasRecording)
+		uploadErr := fmt.Errorf("upload %q for event %d: %w", data.FileToUpload, eventID, err)
+		if auditErr := r.recordUpload(key, data, eventID, hasRecording, paths, err); auditErr != nil {
+			// A change that cannot be audited stops the run, even with -keep-going.
+			return errors.Join(uploadErr, auditErr)
+		}
+		return newEventError(key, event, "upload", uploadErr)
+	}
+	r.Plan.Results[eventID] = domain.NewEventResult(domain.OutcomeUploaded, "", hasRecording)
+	if r.audit != nil {
+		paths.uploaded = r.uploadedRecordingPath(ctx, eventID, data.SeriesID, paths.previous)
+	}
+	return r.recordUpload(key, data, eventID, hasRecording, paths, nil)
+}
+
+// ReportProgress forwards upload progress from the calCMS service to the progress display.
+func (r *Runner) ReportProgress(progress service.UploadProgress) {
+	if r.progress != nil {
+		r.progress.update(progress)
+	}
+}
+
+// plannedBytes estimates the upload volume of the plan from the upload file sizes.
+func (r *Runner) plannedBytes() int64 {
+	var total int64
+	for _, data := range r.Plan.Series {
+		total += fileSize(data.FileToUpload) * int64(len(data.Events))
+	}
+	return total
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
 }
 
 func (r *Runner) sortedSeriesKeys() []string {
@@ -250,7 +561,33 @@ func (r *Runner) sortedSeriesKeys() []string {
 func (r *Runner) eventCount() int {
 	count := 0
 	for _, data := range r.Plan.Series {
-		count += len(data.EventIDs)
+		count += len(data.Events)
 	}
 	return count
 }
+
+// formatEventStart shows the event start in the calCMS time zone, or the raw value if it cannot be parsed.
+func (r *Runner) formatEventStart(event domain.CalCMSEvent) string {
+	start, err := event.Start(r.Cfg.TimeLocation())
+	if err != nil {
+		return event.StartDateTime
+	}
+	return start.Format(eventTimeFormat)
+}
+
+// durationMismatch compares an audio upload with the scheduled event length.
+// Stream files and events without usable times never mismatch.
+func (r *Runner) durationMismatch(data domain.SeriesPlan, event domain.CalCMSEvent) (time.Duration, bool) {
+	if data.AudioDuration <= 0 {
+		return 0, false
+	}
+	scheduled, err := event.Duration(r.Cfg.TimeLocation())
+	if err != nil {
+		return 0, false
+	}
+	difference := data.AudioDuration - scheduled
+	if difference < 0 {
+		difference = -difference
+	}
+	return scheduled, difference > r.Cfg.Uploads.DurationTolerance
+}
@@ -3,12 +3,16 @@ package app
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/johannes-kuhfuss/calcmsfeeder/config"
 	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
 )
 
 func TestEndDateForDurationIsInclusive(t *testing.T) {
@@ -37,23 +41,84 @@ type recordingTestService struct {
 	loginCalls   int
 	checkCalls   int
 	uploadCalls  int
+	queryFrom    time.Time
+	queryTill    time.Time
+	uploadErr    error
+	// failEvents makes the upload of single events fail.
+	failEvents map[int]error
+	// recordings simulates the calCMS recording list per event if it is not nil.
+	recordings map[int][]service.Recording
+	deleted    []string
+	activated  []string
+	// onUpload is called at the start of every upload.
+	onUpload func(eventID int)
+	// seriesNames lists the series calCMS knows by ID.
+	seriesNames map[int]string
 }
 
-func (s *recordingTestService) QueryEvents(time.Time, time.Time) ([]domain.CalCMSEvent, error) {
+func (s *recordingTestService) QueryEvents(_ context.Context, from, till time.Time) ([]domain.CalCMSEvent, error) {
+	s.queryFrom, s.queryTill = from, till
 	return s.events, nil
 }
-func (s *recordingTestService) Login(string, string) error {
+func (s *recordingTestService) Login(context.Context, string, string) error {
 	s.loginCalls++
 	return nil
 }
-func (s *recordingTestService) HasRecording(int, int) (bool, error) {
+func (s *recordingTestService) HasRecording(_ context.Context, eventID, _ int) (bool, error) {
 	s.checkCalls++
+	if s.recordings != nil {
+		return service.ActiveRecording(s.recordings[eventID]) != "", nil
+	}
 	return s.hasRecording, nil
 }
-func (s *recordingTestService) UploadFile(int, int, string) error {
+func (s *recordingTestService) UploadFile(ctx context.Context, eventID, _ int, _ string) error {
 	s.uploadCalls++
+	if s.onUpload != nil {
+		s.onUpload(eventID)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.failEvents[eventID]; err != nil {
+		return err
+	}
+	if s.uploadErr == nil && s.recordings != nil {
+		recordings := s.recordings[eventID]
+		for i := range recordings {
+			recordings[i].Active = false
+		}
+		path := fmt.Sprintf("upload-%d-%d.mp3", eventID, s.uploadCalls)
+		s.recordings[eventID] = append(recordings, service.Recording{Path: path, Active: true})
+	}
+	return s.uploadErr
+}
+func (s *recordingTestService) Recordings(_ context.Context, eventID, _ int) ([]service.Recording, error) {
+	return slices.Clone(s.recordings[eventID]), nil
+}
+
+// DeleteRecording removes a recording. It activates no other recording, so rollback has
+// to activate the previous one itself.
+func (s *recordingTestService) DeleteRecording(_ context.Context, eventID, _ int, path string) error {
+	s.deleted = append(s.deleted, path)
+	s.recordings[eventID] = slices.DeleteFunc(s.recordings[eventID], func(r service.Recording) bool { return r.Path == path })
 	return nil
 }
+func (s *recordingTestService) ActivateRecording(_ context.Context, eventID, _ int, path string) error {
+	s.activated = append(s.activated, path)
+	if !slices.ContainsFunc(s.recordings[eventID], func(r service.Recording) bool { return r.Path == path }) {
+		return &service.RejectedError{Operation: "recording activate", Message: "file not found"}
+	}
+	for i := range s.recordings[eventID] {
+		s.recordings[eventID][i].Active = s.recordings[eventID][i].Path == path
+	}
+	return nil
+}
+func (s *recordingTestService) SeriesName(_ context.Context, seriesID int) (string, error) {
+	if name, ok := s.seriesNames[seriesID]; ok {
+		return name, nil
+	}
+	return "", service.ErrSeriesNotFound
+}
 
 func testRunner(fake *recordingTestService) *Runner {
 	cfg := config.AppConfig{}
@@ -61,6 +126,7 @@ func testRunner(fake *recordingTestService) *Runner {
 	cfg.CalCms.CmsPass = "secret"
 	cfg.CalCms.DefaultDurationInDays = 7
 	cfg.CalCms.MaxDurationInDays = 30
+	cfg.Location = time.UTC
 	cfg.Series = map[string]domain.SeriesInfo{
 		"show": {SeriesID: 99, FileToUpload: "show.stream"},
 	}
@@ -86,10 +152,10 @@ func TestUploadFilesHonorsOverwriteFlag(t *testing.T) {
 			runner := testRunner(fake)
 			runner.Plan.Series["show"] = domain.SeriesPlan{
 				SeriesInfo: domain.SeriesInfo{SeriesID: 99, FileToUpload: "show.stream"},
-				EventIDs:   []int{42},
+				Events:     []domain.CalCMSEvent{{EventID: 42, Skey: "show"}},
 			}
 			runner.Overwrite = tt.overwrite
-			if err := runner.uploadFilesToCalCMS(); err != nil {
+			if err := runner.uploadFilesToCalCMS(t.Context()); err != nil {
 				t.Fatal(err)
 			}
 			if fake.loginCalls != 1 || fake.checkCalls != 1 || fake.uploadCalls != tt.wantUploads {
@@ -104,10 +170,109 @@ func TestRunConsumesPipedInputWithOneScanner(t *testing.T) {
 	runner := testRunner(fake)
 	runner.Input = bufio.NewScanner(strings.NewReader("\n\ny\n"))
 
-	if err := runner.Run(); err != nil {
+	if err := runner.Run(t.Context()); err != nil {
 		t.Fatal(err)
 	}
 	if fake.uploadCalls != 1 {
 		t.Fatalf("upload calls = %d, want 1", fake.uploadCalls)
 	}
+	wantFrom := time.Date(2026, time.July, 21, 0, 0, 0, 0, time.UTC)
+	if !fake.queryFrom.Equal(wantFrom) || !fake.queryTill.Equal(wantFrom.AddDate(0, 0, 7)) {
+		t.Fatalf("queried %v - %v, want seven whole days from %v", fake.queryFrom, fake.queryTill, wantFrom)
+	}
+}
+
+func TestUploadFilesRefusesDurationMismatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		wantUploads int
+	}{
+		{name: "warn uploads anyway", policy: config.DurationMismatchWarn, wantUploads: 2},
+		{name: "refuse skips mismatched event", policy: config.DurationMismatchRefuse, wantUploads: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &recordingTestService{}
+			runner := testRunner(fake)
+			output := &bytes.Buffer{}
+			runner.Output = output
+			runner.Input = bufio.NewScanner(strings.NewReader("y\n"))
+			runner.Cfg.Uploads.DurationTolerance = 2 * time.Minute
+			runner.Cfg.Uploads.DurationMismatch = tt.policy
+			runner.Plan.Series["show"] = domain.SeriesPlan{
+				SeriesInfo: domain.SeriesInfo{SeriesID: 99, FileToUpload: "show.mp3", AudioDuration: 30 * time.Minute},
+				Events: []domain.CalCMSEvent{
+					{EventID: 42, Skey: "show", StartDateTime: "2026-07-21T08:00:00", EndDateTime: "2026-07-21T09:00:00"},
+					{EventID: 43, Skey: "show", StartDateTime: "2026-07-22T08:00:00", EndDateTime: "2026-07-22T08:31:00"},
+				},
+			}
+			if _, err := runner.showStatusAndConfirm(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(output.String(), "Event 42 (2026-07-21 08:00 UTC): file plays 30m0s, event is scheduled for 1h0m0s.") {
+				t.Fatalf("status output does not list the mismatch:\n%s", output)
+			}
+			if strings.Contains(output.String(), "Event 43") {
+				t.Fatalf("status output lists an event within tolerance:\n%s", output)
+			}
+			if err := runner.uploadFilesToCalCMS(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			if fake.uploadCalls != tt.wantUploads {
+				t.Fatalf("upload calls = %d, want %d", fake.uploadCalls, tt.wantUploads)
+			}
+		})
+	}
+}
+
+func TestReadStartDateUsesConfiguredTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		now   time.Time
+		input string
+		want  string
+	}{
+		{name: "UTC evening is already tomorrow in Berlin", now: time.Date(2026, time.July, 20, 22, 30, 0, 0, time.UTC), input: "\n", want: "2026-07-21T00:00:00+02:00"},
+		{name: "night of the spring DST change", now: time.Date(2026, time.March, 28, 23, 30, 0, 0, time.UTC), input: "\n", want: "2026-03-29T00:00:00+01:00"},
+		{name: "explicit date after the autumn DST change", now: time.Date(2026, time.October, 24, 12, 0, 0, 0, time.UTC), input: "2026-10-26\n", want: "2026-10-26T00:00:00+01:00"},
+		{name: "yesterday in Berlin is rejected", now: time.Date(2026, time.July, 20, 22, 30, 0, 0, time.UTC), input: "2026-07-20\n2026-07-21\n", want: "2026-07-21T00:00:00+02:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := testRunner(&recordingTestService{})
+			runner.Cfg.Location = berlin
+			runner.Now = func() time.Time { return tt.now }
+			runner.Input = bufio.NewScanner(strings.NewReader(tt.input))
+			if err := runner.readStartDate(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			if got := runner.Plan.StartDate.Format(time.RFC3339); got != tt.want {
+				t.Fatalf("start date = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationMismatchAcrossDSTChange(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testRunner(&recordingTestService{})
+	runner.Cfg.Location = berlin
+	runner.Cfg.Uploads.DurationTolerance = time.Minute
+	data := domain.SeriesPlan{SeriesInfo: domain.SeriesInfo{AudioDuration: time.Hour}}
+	// 01:30 to 03:30 on the night clocks go forward is one real hour.
+	event := domain.CalCMSEvent{EventID: 1, StartDateTime: "2026-03-29T01:30:00", EndDateTime: "2026-03-29T03:30:00"}
+	if scheduled, mismatch := runner.durationMismatch(data, event); mismatch || scheduled != time.Hour {
+		t.Fatalf("durationMismatch() = %v, %v, want 1h0m0s without mismatch", scheduled, mismatch)
+	}
+	if got := runner.formatEventStart(event); got != "2026-03-29 01:30 CET" {
+		t.Fatalf("formatEventStart() = %q", got)
+	}
 }
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+const auditCommand = "audit"
+
+// newRunID returns an identifier for one run that sorts by start time.
+func newRunID(now time.Time) string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// operatingUser names the local account running the feeder.
+func operatingUser() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// openAudit opens the audit log for this run. Without a configured log nothing is recorded.
+func (r *Runner) openAudit() error {
+	if r.Cfg.Audit.ResolvedFile == "" {
+		return nil
+	}
+	audit, err := service.OpenAuditLog(r.Cfg.Audit.ResolvedFile)
+	if err != nil {
+		return err
+	}
+	r.audit = audit
+	r.checksums = make(map[string]string)
+	fmt.Fprintf(r.Output, "Run ID %v, recorded in audit log \"%v\".\r\n", r.RunID, r.Cfg.Audit.ResolvedFile)
+	return nil
+}
+
+func (r *Runner) closeAudit() {
+	if r.audit != nil {
+		r.audit.Close()
+		r.audit = nil
+	}
+}
+
+// fileChecksum returns the SHA-256 of an upload file, computed once per run.
+func (r *Runner) fileChecksum(path string) (string, error) {
+	if sum, ok := r.checksums[path]; ok {
+		return sum, nil
+	}
+	sum, err := service.FileSHA256(path)
+	if err != nil {
+		return "", fmt.Errorf("checksum upload file %q: %w", path, err)
+	}
+	r.checksums[path] = sum
+	return sum, nil
+}
+
+// recordingPaths are the calCMS recordings an upload replaced and created.
+type recordingPaths struct {
+	previous string
+	uploaded string
+}
+
+// activeRecordingPath looks up the active recording of an event for the audit log.
+// A failed lookup only costs the ability to roll back, so it is reported as a warning.
+func (r *Runner) activeRecordingPath(ctx context.Context, eventID, seriesID int) string {
+	recordings, err := r.Service.Recordings(ctx, eventID, seriesID)
+	if err != nil {
+		fmt.Fprintf(r.Output, "Warning: cannot list recordings of event %d, a rollback will not be possible: %v\r\n", eventID, err)
+		return ""
+	}
+	return service.ActiveRecording(recordings)
+}
+
+// uploadedRecordingPath finds the recording an upload created, which calCMS makes the active one.
+func (r *Runner) uploadedRecordingPath(ctx context.Context, eventID, seriesID int, previous string) string {
+	path := r.activeRecordingPath(ctx, eventID, seriesID)
+	if path != "" && path == previous {
+		fmt.Fprintf(r.Output, "Warning: the upload for event %d did not become the active recording, a rollback will not be possible.\r\n", eventID)
+		return ""
+	}
+	return path
+}
+
+// newAuditEntry fills the fields every audit entry of this run shares.
+func (r *Runner) newAuditEntry(action, seriesKey string, seriesID, eventID int) service.AuditEntry {
+	return service.AuditEntry{
+		Time:          r.Now().UTC(),
+		RunID:         r.RunID,
+		Action:        action,
+		OperatingUser: r.operatingUser,
+		Machine:       r.machine,
+		CmsUser:       r.Cfg.CalCms.CmsUser,
+		CmsHost:       r.Cfg.CalCms.CmsHost,
+		ProjectID:     r.Cfg.CalCms.ProjectID,
+		StudioID:      r.Cfg.CalCms.StudioID,
+		SeriesKey:     seriesKey,
+		SeriesID:      seriesID,
+		EventID:       eventID,
+	}
+}
+
+// recordUpload appends the outcome of one upload call to the audit log.
+func (r *Runner) recordUpload(key string, data domain.SeriesPlan, eventID int, hasRecording bool, paths recordingPaths, uploadErr error) error {
+	if r.audit == nil {
+		return nil
+	}
+	sum, err := r.fileChecksum(data.FileToUpload)
+	if err != nil {
+		return err
+	}
+	entry := r.newAuditEntry(service.AuditUpload, key, data.SeriesID, eventID)
+	entry.File = data.FileToUpload
+	entry.SHA256 = sum
+	entry.PreviousRecording = r.Plan.Results[eventID].Recording
+	entry.PreviousPath = paths.previous
+	entry.UploadedPath = paths.uploaded
+	entry.Overwrite = hasRecording
+	entry.Result = domain.OutcomeUploaded
+	if uploadErr != nil {
+		entry.Result = domain.OutcomeFailed
+		entry.Error = uploadErr.Error()
+	}
+	return r.audit.Append(entry)
+}
+
+// runAudit lists audit log entries, optionally filtered by date, series, or event.
+func runAudit(args []string, output io.Writer) error {
+	flags := flag.NewFlagSet(os.Args[0]+" "+auditCommand, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	envFile := flags.String("config.file", ".env", "Specify location of config file. Default is .env")
+	from := flags.String("from", "", "Only list changes on or after this day, e.g. 2026-10-01, 2026-W43 or 2026-10")
+	till := flags.String("till", "", "Only list changes up to and including this day")
+	series := flags.String("series", "", "Only list changes for this series key")
+	event := flags.Int("event", 0, "Only list changes for this event ID")
+	outputFormat := flags.String("output", OutputText, "Output format: "+strings.Join(outputFormats, ", "))
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usageError(err)
+	}
+	if err := validateOutputFormat(*outputFormat); err != nil {
+		return usageError(err)
+	}
+
+	var cfg config.AppConfig
+	if err := config.InitAuditConfig(*envFile, &cfg); err != nil {
+		return err
+	}
+	filter, err := auditFilter(*from, *till, *series, *event, time.Now())
+	if err != nil {
+		return usageError(err)
+	}
+	entries, err := service.ReadAuditLog(cfg.Audit.ResolvedFile, filter)
+	if err != nil {
+		return err
+	}
+	return writeAuditEntries(output, *outputFormat, entries)
+}
+
+// auditFilter builds the filter from the audit command flags. Days are taken in local time.
+func auditFilter(from, till, series string, event int, now time.Time) (service.AuditFilter, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	filter := service.AuditFilter{SeriesKey: series, EventID: event}
+	if from != "" {
+		days, err := parseDateExpression(from, today)
+		if err != nil {
+			return filter, fmt.Errorf("from: %w", err)
+		}
+		filter.From = days.First
+	}
+	if till != "" {
+		days, err := parseDateExpression(till, today)
+		if err != nil {
+			return filter, fmt.Errorf("till: %w", err)
+		}
+		filter.Till = days.Last.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+var auditHeader = []string{"Time", "Run ID", "Action", "User", "Machine", "calCMS user", "calCMS host", "Project", "Studio", "Series", "Series ID", "Event ID", "File", "SHA-256", "Recording before", "Previous recording", "Uploaded recording", "Overwrite", "Result", "Error"}
+
+func auditFields(entry service.AuditEntry) []string {
+	return []string{
+		entry.Time.Local().Format(time.RFC3339), entry.RunID, auditAction(entry), entry.OperatingUser, entry.Machine, entry.CmsUser, entry.CmsHost,
+		strconv.Itoa(entry.ProjectID), strconv.Itoa(entry.StudioID), entry.SeriesKey, strconv.Itoa(entry.SeriesID), strconv.Itoa(entry.EventID),
+		entry.File, entry.SHA256, string(entry.PreviousRecording), entry.PreviousPath, entry.UploadedPath, strconv.FormatBool(entry.Overwrite), string(entry.Result), entry.Error,
+	}
+}
+
+// auditAction describes an entry's action, naming the run a rollback undid.
+func auditAction(entry service.AuditEntry) string {
+	if entry.RollbackOf != "" {
+		return fmt.Sprintf("%v (rollback of %v)", entry.Action, entry.RollbackOf)
+	}
+	return entry.Action
+}
+
+func writeAuditEntries(w io.Writer, format string, entries []service.AuditEntry) error {
+	if format == OutputJSON {
+		if entries == nil {
+			entries = []service.AuditEntry{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+	if format == OutputText && len(entries) == 0 {
+		_, err := fmt.Fprint(w, "No matching audit entries.\r\n")
+		return err
+	}
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, auditFields(entry))
+	}
+	return writeTable(w, format, "", auditHeader, rows)
+}
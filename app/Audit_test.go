@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+func TestUploadsAreRecordedInAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "show.stream")
+	if err := os.WriteFile(file, []byte("abc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fake := &recordingTestService{hasRecording: true}
+	runner := testRunner(fake)
+	runner.Cfg.CalCms.CmsHost = "https://calcms.example"
+	runner.Cfg.CalCms.ProjectID, runner.Cfg.CalCms.StudioID = 3, 4
+	runner.Cfg.Audit.ResolvedFile = filepath.Join(dir, "audit.jsonl")
+	runner.RunID = "run-1"
+	runner.Overwrite = true
+	runner.Plan.Series["show"] = domain.SeriesPlan{
+		SeriesInfo: domain.SeriesInfo{SeriesID: 99, FileToUpload: file},
+		Events:     []domain.CalCMSEvent{{EventID: 42, Skey: "show"}, {EventID: 43, Skey: "show"}},
+	}
+	if err := runner.uploadFilesToCalCMS(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	fake.uploadErr = errors.New("rejected")
+	runner.RunID = "run-2"
+	if err := runner.uploadFilesToCalCMS(t.Context()); err == nil {
+		t.Fatal("upload error was not returned")
+	}
+
+	entries, err := service.ReadAuditLog(runner.Cfg.Audit.ResolvedFile, service.AuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("audit entries = %+v, want 3", entries)
+	}
+	first := entries[0]
+	if first.RunID != "run-1" || first.EventID != 42 || first.SeriesKey != "show" || first.SeriesID != 99 || first.CmsUser != "user" ||
+		first.CmsHost != "https://calcms.example" || first.ProjectID != 3 || first.StudioID != 4 || first.File != file ||
+		first.SHA256 != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" || first.PreviousRecording != domain.RecordingActive ||
+		!first.Overwrite || first.Result != domain.OutcomeUploaded || first.OperatingUser == "" {
+		t.Fatalf("first entry = %+v", first)
+	}
+	if failed := entries[2]; failed.RunID != "run-2" || failed.Result != domain.OutcomeFailed || failed.Error != "rejected" {
+		t.Fatalf("failed entry = %+v", failed)
+	}
+}
+
+func TestSkippedEventsAreNotAudited(t *testing.T) {
+	dir := t.TempDir()
+	fake := &recordingTestService{hasRecording: true}
+	runner := testRunner(fake)
+	runner.Cfg.Audit.ResolvedFile = filepath.Join(dir, "audit.jsonl")
+	runner.Plan.Series["show"] = domain.SeriesPlan{
+		SeriesInfo: domain.SeriesInfo{SeriesID: 99, FileToUpload: "show.stream"},
+		Events:     []domain.CalCMSEvent{{EventID: 42, Skey: "show"}},
+	}
+	if err := runner.uploadFilesToCalCMS(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := service.ReadAuditLog(runner.Cfg.Audit.ResolvedFile, service.AuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("audit entries = %+v, want none", entries)
+	}
+}
+
+func TestAuditFilterFromFlags(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 15, 0, 0, 0, time.UTC)
+	filter, err := auditFilter("2026-10", "2026-10-05", "show", 42, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filter.From.Equal(time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)) || !filter.Till.Equal(time.Date(2026, time.October, 6, 0, 0, 0, 0, time.UTC)) ||
+		filter.SeriesKey != "show" || filter.EventID != 42 {
+		t.Fatalf("filter = %+v", filter)
+	}
+	if _, err := auditFilter("last year", "", "", 0, now); err == nil {
+		t.Fatal("auditFilter accepted an unknown date")
+	}
+}
+
+func TestWriteAuditEntries(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeAuditEntries(&out, OutputText, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "No matching audit entries.\r\n" {
+		t.Fatalf("output = %q", out.String())
+	}
+	out.Reset()
+	entries := []service.AuditEntry{{Time: time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC), RunID: "run-1", SeriesKey: "show", EventID: 42, Result: domain.OutcomeUploaded}}
+	if err := writeAuditEntries(&out, OutputCSV, entries); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "Time,Run ID,Action,User") || !strings.Contains(lines[1], ",run-1,") || !strings.Contains(lines[1], ",show,0,42,") {
+		t.Fatalf("csv = %q", out.String())
+	}
+}
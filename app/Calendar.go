@@ -0,0 +1,76 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+// exportICalendar writes the plan's events with their outcomes to ICSFile, if one is set.
+func (r *Runner) exportICalendar() error {
+	if r.ICSFile == "" {
+		return nil
+	}
+	events, skipped := r.calendarEvents()
+	file, err := os.Create(r.ICSFile)
+	if err != nil {
+		return fmt.Errorf("create iCalendar file: %w", err)
+	}
+	if err := service.WriteICalendar(file, events, r.Now()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close iCalendar file: %w", err)
+	}
+	fmt.Fprintf(r.Output, "Wrote %d events to \"%v\".\r\n", len(events), r.ICSFile)
+	if skipped > 0 {
+		fmt.Fprintf(r.Output, "Warning: %d events without start time were left out of the calendar.\r\n", skipped)
+	}
+	return nil
+}
+
+// calendarEvents converts the planned events into calendar entries.
+// Events without a usable start time cannot be placed in a calendar and are counted instead.
+func (r *Runner) calendarEvents() ([]service.ICalendarEvent, int) {
+	loc := r.Cfg.TimeLocation()
+	var events []service.ICalendarEvent
+	skipped := 0
+	for _, key := range r.sortedSeriesKeys() {
+		data := r.Plan.Series[key]
+		for _, event := range data.Events {
+			start, err := event.Start(loc)
+			if err != nil {
+				skipped++
+				continue
+			}
+			end, _ := event.End(loc)
+			result := r.Plan.Result(event.EventID)
+			summary := event.Title
+			if summary == "" {
+				summary = key
+			}
+			description := []string{
+				fmt.Sprintf("Series: %v", key),
+				fmt.Sprintf("Event ID: %d", event.EventID),
+				fmt.Sprintf("Upload file: %v", data.FileToUpload),
+				fmt.Sprintf("Outcome: %v", result.Outcome),
+			}
+			if result.Reason != "" {
+				description = append(description, fmt.Sprintf("Reason: %v", result.Reason))
+			}
+			events = append(events, service.ICalendarEvent{
+				UID:         fmt.Sprintf("calcms-event-%d@calcmsfeeder", event.EventID),
+				Start:       start,
+				End:         end,
+				Summary:     summary,
+				Description: strings.Join(description, "\n"),
+				EventID:     event.EventID,
+				Skey:        key,
+			})
+		}
+	}
+	return events, skipped
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func TestRunExportsICalendarWithOutcomes(t *testing.T) {
+	fake := &recordingTestService{events: []domain.CalCMSEvent{
+		{EventID: 42, Skey: "show", Title: "Show: Pilot", StartDateTime: "2026-07-21T08:00:00", EndDateTime: "2026-07-21T09:00:00"},
+		{EventID: 43, Skey: "show", StartDateTime: "2026-07-22T08:00:00", EndDateTime: "2026-07-22T09:00:00"},
+		{EventID: 44, Skey: "show"},
+	}}
+	runner := testRunner(fake)
+	runner.Input = bufio.NewScanner(strings.NewReader("\n\ny\n"))
+	runner.ICSFile = filepath.Join(t.TempDir(), "plan.ics")
+
+	if err := runner.Run(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(runner.ICSFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calendar := strings.ReplaceAll(string(data), "\r\n ", "")
+	if got := strings.Count(calendar, "BEGIN:VEVENT"); got != 2 {
+		t.Fatalf("calendar has %d events, want 2 with start times:\n%s", got, calendar)
+	}
+	for _, want := range []string{"UID:calcms-event-42@calcmsfeeder", "SUMMARY:Show: Pilot", "SUMMARY:show", `Event ID: 43\nUpload file: show.stream\nOutcome: uploaded`} {
+		if !strings.Contains(calendar, want) {
+			t.Fatalf("calendar does not contain %q:\n%s", want, calendar)
+		}
+	}
+}
+
+func TestRunDoesNotExportICalendarWhenAborted(t *testing.T) {
+	fake := &recordingTestService{events: []domain.CalCMSEvent{
+		{EventID: 42, Skey: "show", StartDateTime: "2026-07-21T08:00:00", EndDateTime: "2026-07-21T09:00:00"},
+	}}
+	runner := testRunner(fake)
+	runner.Input = bufio.NewScanner(strings.NewReader("\n\nn\n"))
+	runner.ICSFile = filepath.Join(t.TempDir(), "plan.ics")
+
+	if err := runner.Run(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(runner.ICSFile); !os.IsNotExist(err) {
+		t.Fatalf("iCalendar file exists after abort, stat error = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+const checkCommand = "check"
+
+// ErrCheckFailed marks a check that found problems in the configuration.
+var ErrCheckFailed = errors.New("configuration check found problems")
+
+var checkHeader = []string{"Series", "Series ID", "calCMS name", "Past events", "Upcoming events", "Findings"}
+
+// seriesCheck is the result of checking one configured series against calCMS.
+type seriesCheck struct {
+	key      string
+	seriesID int
+	name     string
+	past     int
+	upcoming int
+	// undated are the IDs of events whose start time cannot be read.
+	undated  []int
+	problems []string
+}
+
+// runCheck validates the configuration against the live calCMS without uploading.
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet(os.Args[0]+" "+checkCommand, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	envFile := flags.String("config.file", ".env", "Specify location of config file. Default is .env")
+	pastDays := flags.Int("past-days", 28, "Look for events of each series this many days back")
+	futureDays := flags.Int("future-days", 28, "Look for events of each series this many days ahead")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usageError(err)
+	}
+	if *pastDays < 0 || *futureDays < 0 || *pastDays+*futureDays == 0 {
+		return usageError(fmt.Errorf("-past-days and -future-days must not be negative and must not both be 0"))
+	}
+
+	var cfg config.AppConfig
+	if err := config.InitConfig(*envFile, &cfg); err != nil {
+		return err
+	}
+	runner := NewRunner(cfg, os.Stdin, os.Stdout, time.Now)
+	runner.Service = service.NewCalCmsService(&runner.Cfg)
+	return withInterrupts(runner, func(ctx context.Context) error {
+		return runner.Check(ctx, *pastDays, *futureDays)
+	})
+}
+
+// Check logs into calCMS, looks up every configured series ID, and queries the calCMS events
+// of the last pastDays and the next futureDays days to see whether every series key appears.
+// It reports each series and returns ErrCheckFailed if anything looks wrong.
+func (r *Runner) Check(ctx context.Context, pastDays, futureDays int) error {
+	if err := r.Service.Login(ctx, r.Cfg.CalCms.CmsUser, r.Cfg.CalCms.CmsPass); err != nil {
+		return fmt.Errorf("log in to calCMS: %w", err)
+	}
+	fmt.Fprintf(r.Output, "Logged in to %v as %v.\r\n", r.Cfg.CalCms.CmsHost, r.Cfg.CalCms.CmsUser)
+
+	keys := r.sortedSeriesKeys()
+	checks := make(map[string]*seriesCheck, len(keys))
+	keysByID := make(map[int][]string)
+	for _, key := range keys {
+		check := &seriesCheck{key: key, seriesID: r.Plan.Series[key].SeriesID}
+		checks[key] = check
+		keysByID[check.seriesID] = append(keysByID[check.seriesID], key)
+		name, err := r.Service.SeriesName(ctx, check.seriesID)
+		switch {
+		case errors.Is(err, service.ErrSeriesNotFound):
+			check.problems = append(check.problems, fmt.Sprintf("series ID %d not found in project %d, studio %d: %v", check.seriesID, r.Cfg.CalCms.ProjectID, r.Cfg.CalCms.StudioID, err))
+		case err != nil:
+			return fmt.Errorf("look up series %d of %q: %w", check.seriesID, key, err)
+		default:
+			check.name = name
+		}
+	}
+	for _, key := range keys {
+		if others := keysByID[checks[key].seriesID]; len(others) > 1 {
+			checks[key].problems = append(checks[key].problems, fmt.Sprintf("series ID is shared by %v", strings.Join(others, ", ")))
+		}
+	}
+
+	now := r.Now().In(r.Cfg.TimeLocation())
+	from, till := r.surroundingDays(pastDays, futureDays)
+	events, err := r.Service.QueryEvents(ctx, from, till)
+	if err != nil {
+		return fmt.Errorf("query events from calCMS: %w", err)
+	}
+	r.forgetOtherSeriesKeys()
+	for _, event := range events {
+		check, ok := checks[event.Skey]
+		if !ok {
+			r.noteOtherSeriesKey(event.Skey)
+			continue
+		}
+		start, err := event.Start(r.Cfg.TimeLocation())
+		switch {
+		case err != nil:
+			check.undated = append(check.undated, event.EventID)
+		case start.Before(now):
+			check.past++
+		default:
+			check.upcoming++
+		}
+	}
+	lastDay := till.AddDate(0, 0, -1).Format(dateFormat)
+	others := r.sortedOtherSeriesKeys()
+	for _, key := range keys {
+		check := checks[key]
+		if len(check.undated) > 0 {
+			check.problems = append(check.problems, fmt.Sprintf("events %v have no valid start time", check.undated))
+		}
+		switch {
+		case check.past == 0 && check.upcoming == 0 && len(check.undated) == 0:
+			problem := fmt.Sprintf("no calCMS event between %v and %v has this series key", from.Format(dateFormat), lastDay)
+			if suggestions := similarSeriesKeys(key, others); len(suggestions) > 0 {
+				problem += ", " + didYouMean(suggestions)
+			}
+			check.problems = append(check.problems, problem)
+		case check.upcoming == 0 && futureDays > 0:
+			check.problems = append(check.problems, fmt.Sprintf("no upcoming event until %v", lastDay))
+		}
+	}
+	return r.showCheckResult(keys, checks)
+}
+
+// showCheckResult prints one row per series and summarizes the problems found.
+func (r *Runner) showCheckResult(keys []string, checks map[string]*seriesCheck) error {
+	rows := make([][]string, 0, len(keys))
+	failed := 0
+	for _, key := range keys {
+		check := checks[key]
+		findings := "ok"
+		if len(check.problems) > 0 {
+			failed++
+			findings = strings.Join(check.problems, "; ")
+		}
+		rows = append(rows, []string{check.key, fmt.Sprint(check.seriesID), check.name, fmt.Sprint(check.past), fmt.Sprint(check.upcoming), findings})
+	}
+	writeTable(r.Output, OutputText, "", checkHeader, rows)
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d series", ErrCheckFailed, failed, len(keys))
+	}
+	fmt.Fprintf(r.Output, "All %d series look fine.\r\n", len(keys))
+	return nil
+}
+
+// surroundingDays returns the range from pastDays days before today until the end of
+// the day futureDays days after today.
+func (r *Runner) surroundingDays(pastDays, futureDays int) (time.Time, time.Time) {
+	today := r.today()
+	return today.AddDate(0, 0, -pastDays), today.AddDate(0, 0, futureDays+1)
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func TestCheckReportsSuspiciousSeries(t *testing.T) {
+	fake := &recordingTestService{
+		seriesNames: map[int]string{99: "Show", 100: "Ended"},
+		events: []domain.CalCMSEvent{
+			{EventID: 1, Skey: "show", StartDateTime: "2026-07-14T08:00:00"},
+			{EventID: 2, Skey: "show", StartDateTime: "2026-07-28T08:00:00"},
+			{EventID: 3, Skey: "ended", StartDateTime: "2026-07-01T08:00:00"},
+			{EventID: 4, Skey: "Other show", StartDateTime: "2026-07-22T08:00:00"},
+		},
+	}
+	runner := testRunner(fake)
+	runner.Plan.Series["ended"] = domain.SeriesPlan{SeriesInfo: domain.SeriesInfo{SeriesID: 100}}
+	runner.Plan.Series["typo"] = domain.SeriesPlan{SeriesInfo: domain.SeriesInfo{SeriesID: 101}}
+	output := &bytes.Buffer{}
+	runner.Output = output
+
+	err := runner.Check(t.Context(), 28, 28)
+	if !errors.Is(err, ErrCheckFailed) || ExitCode(err) != ExitCheckFailed {
+		t.Fatalf("Check() error = %v, want ErrCheckFailed", err)
+	}
+	if !strings.Contains(err.Error(), "2 of 3 series") {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if fake.loginCalls != 1 || fake.uploadCalls != 0 {
+		t.Fatalf("calls: login=%d upload=%d, want 1, 0", fake.loginCalls, fake.uploadCalls)
+	}
+	wantFrom := time.Date(2026, time.June, 23, 0, 0, 0, 0, time.UTC)
+	if !fake.queryFrom.Equal(wantFrom) || !fake.queryTill.Equal(time.Date(2026, time.August, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("queried %v - %v", fake.queryFrom, fake.queryTill)
+	}
+	for _, want := range []string{
+		"no upcoming event until 2026-08-18",
+		"series ID 101 not found",
+		"no calCMS event between 2026-06-23 and 2026-08-18 has this series key",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("output misses %q:\n%s", want, output)
+		}
+	}
+	for _, line := range strings.Split(output.String(), "\n") {
+		if strings.HasPrefix(line, "show ") && !strings.HasSuffix(strings.TrimSpace(line), "ok") {
+			t.Fatalf("series show should be ok: %q", line)
+		}
+	}
+}
+
+func TestCheckPassesForValidSeries(t *testing.T) {
+	fake := &recordingTestService{
+		seriesNames: map[int]string{99: "Show"},
+		events:      []domain.CalCMSEvent{{EventID: 2, Skey: "show", StartDateTime: "2026-07-28T08:00:00"}},
+	}
+	runner := testRunner(fake)
+	output := &bytes.Buffer{}
+	runner.Output = output
+	if err := runner.Check(t.Context(), 7, 14); err != nil {
+		t.Fatalf("Check() error = %v\n%s", err, output)
+	}
+	if !strings.Contains(output.String(), "All 1 series look fine.") {
+		t.Fatalf("output = %s", output)
+	}
+}
+
+func TestCheckReportsEventsWithoutStartTime(t *testing.T) {
+	fake := &recordingTestService{
+		seriesNames: map[int]string{99: "Show"},
+		events: []domain.CalCMSEvent{
+			{EventID: 1, Skey: "show", StartDateTime: "2026-07-14T08:00:00"},
+			{EventID: 2, Skey: "show", StartDateTime: "2026-07-28T08:00:00"},
+			{EventID: 3, Skey: "show", StartDateTime: "tomorrow morning"},
+			{EventID: 4, Skey: "show"},
+		},
+	}
+	runner := testRunner(fake)
+	output := &bytes.Buffer{}
+	runner.Output = output
+	err := runner.Check(t.Context(), 28, 28)
+	if !errors.Is(err, ErrCheckFailed) {
+		t.Fatalf("Check() error = %v, want ErrCheckFailed", err)
+	}
+	if !strings.Contains(output.String(), "events [3 4] have no valid start time") {
+		t.Fatalf("output misses the events without start time:\n%s", output)
+	}
+	for _, line := range strings.Split(output.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) > 4 && fields[0] == "show" && (fields[3] != "1" || fields[4] != "1") {
+			t.Fatalf("past and upcoming counts = %v, want 1 and 1", fields[3:5])
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateExpressionHelp lists the accepted date expressions for prompts and errors.
+const dateExpressionHelp = "YYYY-MM-DD, today, tomorrow, a weekday such as next monday, +3d, +2w, an ISO week such as 2026-W43, a month such as 2026-11, or a range FIRST..LAST"
+
+var (
+	offsetPattern  = regexp.MustCompile(`^\+(\d+)([dw])$`)
+	isoWeekPattern = regexp.MustCompile(`^(\d{4})-w(\d{1,2})$`)
+	monthPattern   = regexp.MustCompile(`^\d{4}-\d{2}$`)
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// dayRange is an inclusive range of whole days, both at midnight.
+type dayRange struct {
+	First time.Time
+	Last  time.Time
+}
+
+// isSingleDay reports whether the range covers exactly one day.
+func (d dayRange) isSingleDay() bool {
+	return d.First.Equal(d.Last)
+}
+
+// fromDay drops the days before today from a range that has already started, so the
+// current week or month can be entered as a whole. It reports false if no day is left.
+func (d dayRange) fromDay(today time.Time) (dayRange, bool) {
+	if d.Last.Before(today) {
+		return d, false
+	}
+	if d.First.Before(today) {
+		d.First = today
+	}
+	return d, true
+}
+
+// days returns the number of calendar days in the range.
+func (d dayRange) days() int {
+	return dayCount(d.First, d.Last)
+}
+
+// dayCount returns the number of calendar days from first through last, independent of DST changes.
+func dayCount(first, last time.Time) int {
+	return int(math.Round(last.Sub(first).Hours()/24)) + 1
+}
+
+// parseDateExpression resolves a date expression relative to today, which must be midnight
+// in the calCMS time zone. Weeks, months and FIRST..LAST expressions yield ranges of days.
+func parseDateExpression(expr string, today time.Time) (dayRange, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	if firstText, lastText, ok := strings.Cut(expr, ".."); ok {
+		if strings.Contains(lastText, "..") {
+			return dayRange{}, fmt.Errorf("%q contains more than one range", expr)
+		}
+		first, err := parseDateExpression(firstText, today)
+		if err != nil {
+			return dayRange{}, err
+		}
+		last, err := parseDateExpression(lastText, today)
+		if err != nil {
+			return dayRange{}, err
+		}
+		if last.Last.Before(first.First) {
+			return dayRange{}, fmt.Errorf("range %q ends before it starts", expr)
+		}
+		return dayRange{First: first.First, Last: last.Last}, nil
+	}
+	day, err := parseSingleDateExpression(expr, today)
+	if err != nil {
+		return dayRange{}, err
+	}
+	if !day.IsZero() {
+		return dayRange{First: day, Last: day}, nil
+	}
+	if m := isoWeekPattern.FindStringSubmatch(expr); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		monday, err := isoWeekStart(year, week, today.Location())
+		if err != nil {
+			return dayRange{}, err
+		}
+		return dayRange{First: monday, Last: monday.AddDate(0, 0, 6)}, nil
+	}
+	if monthPattern.MatchString(expr) {
+		first, err := time.ParseInLocation("2006-01", expr, today.Location())
+		if err != nil {
+			return dayRange{}, fmt.Errorf("%q is not a valid month", expr)
+		}
+		return dayRange{First: first, Last: first.AddDate(0, 1, -1)}, nil
+	}
+	return dayRange{}, fmt.Errorf("%q is not a date, use %s", expr, dateExpressionHelp)
+}
+
+// parseSingleDateExpression resolves expressions naming one day. It returns the zero time
+// without error if expr is not such an expression.
+func parseSingleDateExpression(expr string, today time.Time) (time.Time, error) {
+	switch expr {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if m := offsetPattern.FindStringSubmatch(expr); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("offset %q is too large", expr)
+		}
+		if m[2] == "w" {
+			n *= 7
+		}
+		return today.AddDate(0, 0, n), nil
+	}
+	name, next := strings.CutPrefix(expr, "next ")
+	if weekday, ok := weekdayNames[strings.TrimSpace(name)]; ok {
+		ahead := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if next && ahead == 0 {
+			ahead = 7
+		}
+		return today.AddDate(0, 0, ahead), nil
+	}
+	if d, err := time.ParseInLocation(dateFormat, expr, today.Location()); err == nil {
+		return d, nil
+	}
+	return time.Time{}, nil
+}
+
+// isoWeekStart returns the Monday of an ISO 8601 week.
+func isoWeekStart(year, week int, loc *time.Location) (time.Time, error) {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+	if y, w := monday.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, fmt.Errorf("%d has no ISO week %d", year, week)
+	}
+	return monday, nil
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDateExpression(t *testing.T) {
+	// 2026-07-21 is a Tuesday.
+	today := time.Date(2026, time.July, 21, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr      string
+		wantFirst string
+		wantLast  string
+		wantErr   bool
+	}{
+		{expr: "2026-08-03", wantFirst: "2026-08-03", wantLast: "2026-08-03"},
+		{expr: "today", wantFirst: "2026-07-21", wantLast: "2026-07-21"},
+		{expr: "Tomorrow", wantFirst: "2026-07-22", wantLast: "2026-07-22"},
+		{expr: "monday", wantFirst: "2026-07-27", wantLast: "2026-07-27"},
+		{expr: "next monday", wantFirst: "2026-07-27", wantLast: "2026-07-27"},
+		{expr: "tuesday", wantFirst: "2026-07-21", wantLast: "2026-07-21"},
+		{expr: "next tue", wantFirst: "2026-07-28", wantLast: "2026-07-28"},
+		{expr: "+3d", wantFirst: "2026-07-24", wantLast: "2026-07-24"},
+		{expr: "+2w", wantFirst: "2026-08-04", wantLast: "2026-08-04"},
+		{expr: "2026-W43", wantFirst: "2026-10-19", wantLast: "2026-10-25"},
+		{expr: "2026-W1", wantFirst: "2025-12-29", wantLast: "2026-01-04"},
+		{expr: "2026-W53", wantFirst: "2026-12-28", wantLast: "2027-01-03"},
+		{expr: "2027-W53", wantErr: true},
+		{expr: "2026-11", wantFirst: "2026-11-01", wantLast: "2026-11-30"},
+		{expr: "2026-11-01..2026-11-30", wantFirst: "2026-11-01", wantLast: "2026-11-30"},
+		{expr: "tomorrow..+1w", wantFirst: "2026-07-22", wantLast: "2026-07-28"},
+		{expr: "2026-W43..2026-W44", wantFirst: "2026-10-19", wantLast: "2026-11-01"},
+		{expr: "2026-11-30..2026-11-01", wantErr: true},
+		{expr: "2026-11-01..2026-11-05..2026-11-09", wantErr: true},
+		{expr: "someday", wantErr: true},
+		{expr: "2026-13", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parseDateExpression(tt.expr, today)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseDateExpression(%q) = %v, want error", tt.expr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first, last := got.First.Format(dateFormat), got.Last.Format(dateFormat); first != tt.wantFirst || last != tt.wantLast {
+				t.Fatalf("parseDateExpression(%q) = %s..%s, want %s..%s", tt.expr, first, last, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestInteractiveRangeSkipsDuration(t *testing.T) {
+	fake := &recordingTestService{}
+	runner := testRunner(fake)
+	runner.Input = bufio.NewScanner(strings.NewReader("2026-08..2026-08-10\n"))
+	if err := runner.getUserInput(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	wantFrom := time.Date(2026, time.August, 1, 0, 0, 0, 0, time.UTC)
+	wantTill := time.Date(2026, time.August, 11, 0, 0, 0, 0, time.UTC)
+	if !runner.Plan.From.Equal(wantFrom) || !runner.Plan.Till.Equal(wantTill) {
+		t.Fatalf("range = %v - %v, want %v - %v", runner.Plan.From, runner.Plan.Till, wantFrom, wantTill)
+	}
+}
+
+func TestInteractiveRangeRespectsMaximum(t *testing.T) {
+	runner := testRunner(&recordingTestService{})
+	output := &bytes.Buffer{}
+	runner.Output = output
+	runner.Cfg.CalCms.MaxDurationInDays = 14
+	runner.Input = bufio.NewScanner(strings.NewReader("2026-08\nnext monday\n3\n"))
+	if err := runner.getUserInput(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if got := runner.Plan.StartDate.Format(dateFormat) + ".." + runner.Plan.EndDate.Format(dateFormat); got != "2026-07-27..2026-07-29" {
+		t.Fatalf("range = %s, want 2026-07-27..2026-07-29", got)
+	}
+	if !strings.Contains(output.String(), "Range covers 31 days, the maximum is 14.") {
+		t.Fatal("missing message about the too long range")
+	}
+}
+
+func TestInteractiveCurrentMonthStartsToday(t *testing.T) {
+	runner := testRunner(&recordingTestService{})
+	output := &bytes.Buffer{}
+	runner.Output = output
+	runner.Input = bufio.NewScanner(strings.NewReader("2026-06\n2026-07\n"))
+	if err := runner.getUserInput(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if got := runner.Plan.StartDate.Format(dateFormat) + ".." + runner.Plan.EndDate.Format(dateFormat); got != "2026-07-21..2026-07-31" {
+		t.Fatalf("range = %s, want 2026-07-21..2026-07-31", got)
+	}
+	if !strings.Contains(output.String(), "Range must not lie in the past") {
+		t.Fatal("missing message about the past month")
+	}
+}
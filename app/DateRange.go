@@ -0,0 +1,160 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+const timeOfDayFormat = "15:04"
+
+// dateTimeLayouts are accepted for exact start and end times on the command line.
+var dateTimeLayouts = []string{"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// parseDateTime parses a date with time of day in loc.
+func parseDateTime(value string, loc *time.Location) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTimeWindow parses a daily window such as "06:00-10:00". The end is exclusive and may be past midnight.
+func parseTimeWindow(value string) (domain.TimeWindow, error) {
+	fromText, tillText, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return domain.TimeWindow{}, fmt.Errorf("time window %q must look like HH:MM-HH:MM", value)
+	}
+	from, err := parseTimeOfDay(fromText)
+	if err != nil {
+		return domain.TimeWindow{}, err
+	}
+	till, err := parseTimeOfDay(tillText)
+	if err != nil {
+		return domain.TimeWindow{}, err
+	}
+	if from == till {
+		return domain.TimeWindow{}, fmt.Errorf("time window %q is empty", value)
+	}
+	return domain.TimeWindow{From: from, Till: till}, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 0, nil
+	}
+	t, err := time.Parse(timeOfDayFormat, value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day (HH:MM)", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatTimeOfDay(offset time.Duration) string {
+	return time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Add(offset).Format(timeOfDayFormat)
+}
+
+// SetRange selects the processing range from command-line values instead of asking for it.
+// start and end accept date-times and date expressions; an end day is included completely,
+// an end date-time is exclusive. A start expression naming several days, such as a week,
+// a month or FIRST..LAST, is a complete range. Without end, days selects the number of days
+// from start.
+func (r *Runner) SetRange(start, end string, days int) error {
+	if end != "" && days != 0 {
+		return fmt.Errorf("use either an end or a number of days")
+	}
+	loc := r.Cfg.TimeLocation()
+	today := r.today()
+	from, exact := parseDateTime(start, loc)
+	if !exact {
+		startDays, err := parseDateExpression(start, today)
+		if err != nil {
+			return fmt.Errorf("start: %w", err)
+		}
+		if !startDays.isSingleDay() {
+			if end != "" || days != 0 {
+				return fmt.Errorf("start %q is already a range, -end and -days cannot be combined with it", start)
+			}
+			remaining, ok := startDays.fromDay(today)
+			if !ok {
+				return fmt.Errorf("the range %q lies in the past", start)
+			}
+			return r.setExactRange(remaining.First, remaining.Last.AddDate(0, 0, 1))
+		}
+		from = startDays.First
+	}
+	var till time.Time
+	switch {
+	case end != "":
+		var exactEnd bool
+		if till, exactEnd = parseDateTime(end, loc); !exactEnd {
+			endDays, err := parseDateExpression(end, today)
+			if err != nil {
+				return fmt.Errorf("end: %w", err)
+			}
+			till = endDays.Last.AddDate(0, 0, 1)
+		}
+	default:
+		if days == 0 {
+			days = r.Cfg.CalCms.DefaultDurationInDays
+		}
+		startDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+		till = startDay.AddDate(0, 0, days)
+	}
+	return r.setExactRange(from, till)
+}
+
+// SetDailyWindow restricts the run to events starting within a time-of-day window such as "06:00-10:00".
+func (r *Runner) SetDailyWindow(window string) error {
+	w, err := parseTimeWindow(window)
+	if err != nil {
+		return err
+	}
+	r.Plan.DailyWindow = w
+	return nil
+}
+
+// setExactRange validates the range from..till and stores it in the plan.
+func (r *Runner) setExactRange(from, till time.Time) error {
+	if !from.Before(till) {
+		return fmt.Errorf("the end must be after the start")
+	}
+	loc := r.Cfg.TimeLocation()
+	if from.Before(r.today()) {
+		return fmt.Errorf("the start must be today or later")
+	}
+	startDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	lastDay := till.Add(-time.Nanosecond)
+	lastDay = time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 0, 0, 0, 0, loc)
+	if days := dayCount(startDay, lastDay); days > r.Cfg.CalCms.MaxDurationInDays {
+		return fmt.Errorf("the range covers %d days, the maximum is %d", days, r.Cfg.CalCms.MaxDurationInDays)
+	}
+	r.Plan.StartDate, r.Plan.EndDate = startDay, lastDay
+	r.Plan.From, r.Plan.Till = from, till
+	r.rangeSet = true
+	return nil
+}
+
+// today returns midnight of the current day in the calCMS time zone.
+func (r *Runner) today() time.Time {
+	now := r.Now().In(r.Cfg.TimeLocation())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// setDayRange covers the inclusive days startDate..endDate completely.
+func (r *Runner) setDayRange(startDate, endDate time.Time) {
+	r.Plan.StartDate, r.Plan.EndDate = startDate, endDate
+	r.Plan.From = startDate
+	r.Plan.Till = endDate.AddDate(0, 0, 1)
+}
+
+// isWholeDay reports whether t is midnight in the calCMS time zone.
+func isWholeDay(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func TestSetRange(t *testing.T) {
+	day := func(d, h, m int) time.Time { return time.Date(2026, time.July, d, h, m, 0, 0, time.UTC) }
+	tests := []struct {
+		name     string
+		start    string
+		end      string
+		days     int
+		wantFrom time.Time
+		wantTill time.Time
+		wantErr  bool
+	}{
+		{name: "end date is inclusive", start: "2026-07-22", end: "2026-07-24", wantFrom: day(22, 0, 0), wantTill: day(25, 0, 0)},
+		{name: "end date-time is exclusive", start: "2026-07-22T06:00", end: "2026-07-22 10:30", wantFrom: day(22, 6, 0), wantTill: day(22, 10, 30)},
+		{name: "days from start", start: "2026-07-22T18:00", days: 2, wantFrom: day(22, 18, 0), wantTill: day(24, 0, 0)},
+		{name: "default duration", start: "2026-07-22", wantFrom: day(22, 0, 0), wantTill: day(29, 0, 0)},
+		{name: "start week", start: "2026-W31", wantFrom: day(27, 0, 0), wantTill: time.Date(2026, time.August, 3, 0, 0, 0, 0, time.UTC)},
+		{name: "start range", start: "tomorrow..+3d", wantFrom: day(22, 0, 0), wantTill: day(25, 0, 0)},
+		{name: "end weekday before start", start: "next monday", end: "next friday", wantErr: true},
+		{name: "relative end", start: "tomorrow", end: "+1w", wantFrom: day(22, 0, 0), wantTill: day(29, 0, 0)},
+		{name: "range and end", start: "2026-W31", end: "2026-08-10", wantErr: true},
+		{name: "start later today", start: "2026-07-21T06:00", end: "2026-07-21T07:00", wantFrom: day(21, 6, 0), wantTill: day(21, 7, 0)},
+		{name: "start in the past", start: "2026-07-20", wantErr: true},
+		{name: "current month", start: "2026-07", wantFrom: day(21, 0, 0), wantTill: time.Date(2026, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "current week", start: "2026-W30", wantFrom: day(21, 0, 0), wantTill: day(27, 0, 0)},
+		{name: "range ending today", start: "2026-07-01..2026-07-21", wantFrom: day(21, 0, 0), wantTill: day(22, 0, 0)},
+		{name: "past month", start: "2026-06", wantErr: true},
+		{name: "end before start", start: "2026-07-22T10:00", end: "2026-07-22T09:00", wantErr: true},
+		{name: "end and days", start: "2026-07-22", end: "2026-07-24", days: 2, wantErr: true},
+		{name: "too many days", start: "2026-07-22", days: 31, wantErr: true},
+		{name: "invalid start", start: "tomorrow-ish", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := testRunner(&recordingTestService{})
+			err := runner.SetRange(tt.start, tt.end, tt.days)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("SetRange() accepted %q - %q", tt.start, tt.end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !runner.Plan.From.Equal(tt.wantFrom) || !runner.Plan.Till.Equal(tt.wantTill) {
+				t.Fatalf("range = %v - %v, want %v - %v", runner.Plan.From, runner.Plan.Till, tt.wantFrom, tt.wantTill)
+			}
+		})
+	}
+}
+
+func TestParseTimeWindow(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    domain.TimeWindow
+		wantErr bool
+	}{
+		{value: "06:00-10:00", want: domain.TimeWindow{From: 6 * time.Hour, Till: 10 * time.Hour}},
+		{value: "22:00-02:00", want: domain.TimeWindow{From: 22 * time.Hour, Till: 2 * time.Hour}},
+		{value: "18:30-24:00", want: domain.TimeWindow{From: 18*time.Hour + 30*time.Minute}},
+		{value: "06:00", wantErr: true},
+		{value: "06:00-06:00", wantErr: true},
+		{value: "6am-10am", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeWindow(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Fatalf("parseTimeWindow(%q) = %+v, %v", tt.value, got, err)
+		}
+	}
+}
+
+func TestDailyWindowFiltersEvents(t *testing.T) {
+	fake := &recordingTestService{events: []domain.CalCMSEvent{
+		{EventID: 1, Skey: "show", StartDateTime: "2026-07-22T05:59:00"},
+		{EventID: 2, Skey: "show", StartDateTime: "2026-07-22T06:00:00"},
+		{EventID: 3, Skey: "show", StartDateTime: "2026-07-23T09:59:00"},
+		{EventID: 4, Skey: "show", StartDateTime: "2026-07-23T10:00:00"},
+		{EventID: 5, Skey: "show"},
+	}}
+	runner := testRunner(fake)
+	if err := runner.SetRange("2026-07-22", "2026-07-23", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.SetDailyWindow("06:00-10:00"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.queryCalCMSEvents(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(runner.Plan.Series["show"].EventIDs()); got != "[2 3]" {
+		t.Fatalf("event IDs = %s, want [2 3]", got)
+	}
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+const discoverCommand = "discover"
+
+var discoverHeader = []string{"#", "Series key", "Series ID", "Series name", "Events", "Configured"}
+
+// discoveredSeries is one distinct series key found in the calCMS events.
+type discoveredSeries struct {
+	key        string
+	seriesIDs  []int
+	name       string
+	events     int
+	configured bool
+}
+
+// runDiscover lists the series found in the calCMS events and optionally writes a starter configuration.
+func runDiscover(args []string) error {
+	flags := flag.NewFlagSet(os.Args[0]+" "+discoverCommand, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	envFile := flags.String("config.file", ".env", "Specify location of config file. Default is .env")
+	pastDays := flags.Int("past-days", 28, "List series with events up to this many days back")
+	futureDays := flags.Int("future-days", 28, "List series with events up to this many days ahead")
+	outputFormat := flags.String("output", OutputText, "Format of the series list: "+strings.Join(outputFormats, ", "))
+	writeFile := flags.String("write", "", "Ask which series to keep and write their SERIES_FILES and SERIES_IDS entries to this new file")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usageError(err)
+	}
+	if *pastDays < 0 || *futureDays < 0 || *pastDays+*futureDays == 0 {
+		return usageError(fmt.Errorf("-past-days and -future-days must not be negative and must not both be 0"))
+	}
+	if err := validateOutputFormat(*outputFormat); err != nil {
+		return usageError(err)
+	}
+
+	var cfg config.AppConfig
+	if err := config.InitCalCmsConfig(*envFile, &cfg); err != nil {
+		return err
+	}
+	runner := NewRunner(cfg, os.Stdin, os.Stdout, time.Now)
+	runner.OutputFormat = *outputFormat
+	if *outputFormat != OutputText {
+		runner.Output = os.Stderr
+		runner.Report = os.Stdout
+	}
+	runner.Service = service.NewCalCmsService(&runner.Cfg)
+	return withInterrupts(runner, func(ctx context.Context) error {
+		return runner.Discover(ctx, *pastDays, *futureDays, *writeFile)
+	})
+}
+
+// Discover queries the calCMS events of the last pastDays and the next futureDays days and
+// lists every distinct series key with its series ID, name and number of events. With a
+// configFile it asks which series to keep and writes a starter configuration for them.
+func (r *Runner) Discover(ctx context.Context, pastDays, futureDays int, configFile string) error {
+	from, till := r.surroundingDays(pastDays, futureDays)
+	events, err := r.Service.QueryEvents(ctx, from, till)
+	if err != nil {
+		return fmt.Errorf("query events from calCMS: %w", err)
+	}
+	lastDay := till.AddDate(0, 0, -1).Format(dateFormat)
+	series := discoverSeries(events, r.Cfg.CalCms.SeriesIDs)
+	if len(series) == 0 {
+		fmt.Fprintf(r.Output, "calCMS has no events between %v and %v.\r\n", from.Format(dateFormat), lastDay)
+		return nil
+	}
+	w := r.Report
+	if w == nil {
+		w = r.Output
+	}
+	if r.OutputFormat == OutputText || r.OutputFormat == "" {
+		fmt.Fprintf(w, "Series with events between %v and %v:\r\n", from.Format(dateFormat), lastDay)
+	}
+	if err := writeTable(w, r.OutputFormat, "Series", discoverHeader, discoverRows(series)); err != nil {
+		return fmt.Errorf("write series list: %w", err)
+	}
+	if configFile == "" {
+		return nil
+	}
+	picked, err := r.pickSeries(ctx, series)
+	if err != nil {
+		return err
+	}
+	if len(picked) == 0 {
+		fmt.Fprint(r.Output, "No series selected, nothing written.\r\n")
+		return nil
+	}
+	content, written := r.starterConfig(picked, from.Format(dateFormat), lastDay)
+	if written == 0 {
+		return fmt.Errorf("none of the selected series can be written to %q", configFile)
+	}
+	file, err := os.OpenFile(configFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create series configuration: %w", err)
+	}
+	if _, err := io.WriteString(file, content); err != nil {
+		file.Close()
+		return fmt.Errorf("write series configuration %q: %w", configFile, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("write series configuration %q: %w", configFile, err)
+	}
+	fmt.Fprintf(r.Output, "Wrote %d series to \"%v\". Point every series at its upload file before use.\r\n", written, configFile)
+	return nil
+}
+
+// discoverSeries groups events by series key, sorted by key.
+func discoverSeries(events []domain.CalCMSEvent, configured map[string]int) []discoveredSeries {
+	byKey := make(map[string]*discoveredSeries)
+	for _, event := range events {
+		series, ok := byKey[event.Skey]
+		if !ok {
+			_, isConfigured := configured[event.Skey]
+			series = &discoveredSeries{key: event.Skey, configured: isConfigured}
+			byKey[event.Skey] = series
+		}
+		series.events++
+		if id := int(event.SeriesID); id > 0 && !slices.Contains(series.seriesIDs, id) {
+			series.seriesIDs = append(series.seriesIDs, id)
+			sort.Ints(series.seriesIDs)
+		}
+		if series.name == "" {
+			series.name = event.SeriesName
+		}
+	}
+	list := make([]discoveredSeries, 0, len(byKey))
+	for _, series := range byKey {
+		list = append(list, *series)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].key < list[j].key })
+	return list
+}
+
+func discoverRows(series []discoveredSeries) [][]string {
+	rows := make([][]string, 0, len(series))
+	for i, entry := range series {
+		ids := make([]string, 0, len(entry.seriesIDs))
+		for _, id := range entry.seriesIDs {
+			ids = append(ids, fmt.Sprint(id))
+		}
+		configured := "no"
+		if entry.configured {
+			configured = "yes"
+		}
+		rows = append(rows, []string{fmt.Sprint(i + 1), entry.key, strings.Join(ids, ", "), entry.name, fmt.Sprint(entry.events), configured})
+	}
+	return rows
+}
+
+// pickSeries asks for the numbers of the series to write until the answer is valid.
+func (r *Runner) pickSeries(ctx context.Context, series []discoveredSeries) ([]discoveredSeries, error) {
+	for {
+		fmt.Fprint(r.Output, "Enter the numbers of the series to write (e.g. 1 3-5, \"all\"), or press Enter to write none: ")
+		answer, err := r.readLine(ctx, "read series selection")
+		if err != nil {
+			return nil, err
+		}
+		picked, err := parseSeriesPicks(strings.TrimSpace(answer), series)
+		if err != nil {
+			fmt.Fprintf(r.Output, "%v\r\n", err)
+			continue
+		}
+		return picked, nil
+	}
+}
+
+// parseSeriesPicks selects series by number, by range of numbers, or all of them.
+func parseSeriesPicks(answer string, series []discoveredSeries) ([]discoveredSeries, error) {
+	if strings.EqualFold(answer, "all") {
+		return series, nil
+	}
+	selected := make(map[int]bool)
+	for _, token := range strings.Fields(strings.ReplaceAll(answer, ",", " ")) {
+		first, last, ok := parseNumberRange(token)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a series number", token)
+		}
+		if first < 1 || last > len(series) || first > last {
+			return nil, fmt.Errorf("%q is outside the series numbers 1 .. %v", token, len(series))
+		}
+		for _, index := range numberIndexes(first, last) {
+			selected[index] = true
+		}
+	}
+	var picked []discoveredSeries
+	for i, entry := range series {
+		if selected[i] {
+			picked = append(picked, entry)
+		}
+	}
+	return picked, nil
+}
+
+// starterConfig renders SERIES_FILES and SERIES_IDS for the picked series. Series whose key
+// cannot be written to an environment map, or whose series ID is unknown or ambiguous,
+// are left out with a warning. It returns the content and the number of series written.
+func (r *Runner) starterConfig(picked []discoveredSeries, firstDay, lastDay string) (string, int) {
+	var files, ids []string
+	for _, series := range picked {
+		switch {
+		case strings.ContainsAny(series.key, ":,\"") || strings.TrimSpace(series.key) != series.key || series.key == "":
+			fmt.Fprintf(r.Output, "Warning: series key %q cannot be written to SERIES_FILES, leaving it out.\r\n", series.key)
+			continue
+		case len(series.seriesIDs) == 0:
+			fmt.Fprintf(r.Output, "Warning: calCMS events of %q have no series ID, leaving it out.\r\n", series.key)
+			continue
+		case len(series.seriesIDs) > 1:
+			fmt.Fprintf(r.Output, "Warning: events of %q belong to several series IDs %v, leaving it out.\r\n", series.key, series.seriesIDs)
+			continue
+		}
+		files = append(files, fmt.Sprintf("%v:./uploadfiles/%v.stream", series.key, fileSlug(series.key)))
+		ids = append(ids, fmt.Sprintf("%v:%d", series.key, series.seriesIDs[0]))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Series found in the calCMS events between %v and %v by \"discover\".\n", firstDay, lastDay)
+	b.WriteString("# Copy these lines into the environment file and point every series at its upload file.\n")
+	fmt.Fprintf(&b, "SERIES_FILES=\"%v\"\n", strings.Join(files, ","))
+	fmt.Fprintf(&b, "SERIES_IDS=\"%v\"\n", strings.Join(ids, ","))
+	return b.String(), len(ids)
+}
+
+// fileSlug turns a series key into a lower-case file name.
+func fileSlug(key string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(key) {
+		if c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			b.WriteRune(c)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "series"
+	}
+	return slug
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func discoverTestRunner(input string) (*Runner, *bytes.Buffer) {
+	fake := &recordingTestService{events: []domain.CalCMSEvent{
+		{EventID: 1, Skey: "Radio Zett!", SeriesID: 396, SeriesName: "Radio Zett!"},
+		{EventID: 2, Skey: "Morgenmagazin", SeriesID: 395, SeriesName: "Morgenmagazin"},
+		{EventID: 3, Skey: "Radio Zett!", SeriesID: 396},
+		{EventID: 4, Skey: "Talk: live", SeriesID: 410},
+		{EventID: 5, Skey: "Moved", SeriesID: 411},
+		{EventID: 6, Skey: "Moved", SeriesID: 412},
+	}}
+	runner := testRunner(fake)
+	runner.Cfg.CalCms.SeriesIDs = map[string]int{"Morgenmagazin": 395}
+	runner.Input = bufio.NewScanner(strings.NewReader(input))
+	output := &bytes.Buffer{}
+	runner.Output = output
+	return runner, output
+}
+
+func TestDiscoverListsSeries(t *testing.T) {
+	runner, output := discoverTestRunner("")
+	runner.OutputFormat = OutputCSV
+	if err := runner.Discover(t.Context(), 28, 28, ""); err != nil {
+		t.Fatal(err)
+	}
+	want := "#,Series key,Series ID,Series name,Events,Configured\n" +
+		"1,Morgenmagazin,395,Morgenmagazin,1,yes\n" +
+		"2,Moved,\"411, 412\",,2,no\n" +
+		"3,Radio Zett!,396,Radio Zett!,2,no\n" +
+		"4,Talk: live,410,,1,no\n"
+	if output.String() != want {
+		t.Fatalf("output =\n%s\nwant\n%s", output, want)
+	}
+}
+
+func TestDiscoverWritesStarterConfig(t *testing.T) {
+	runner, output := discoverTestRunner("7\n2-4\n3\n")
+	path := filepath.Join(t.TempDir(), "series.env")
+	if err := runner.Discover(t.Context(), 28, 28, path); err != nil {
+		t.Fatalf("Discover() error = %v\n%s", err, output)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`SERIES_FILES="Radio Zett!:./uploadfiles/radio-zett.stream"`,
+		`SERIES_IDS="Radio Zett!:396"`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Fatalf("config misses %q:\n%s", want, content)
+		}
+	}
+	for _, want := range []string{
+		`"7" is outside the series numbers 1 .. 4`,
+		`Warning: events of "Moved" belong to several series IDs [411 412], leaving it out.`,
+		`Warning: series key "Talk: live" cannot be written to SERIES_FILES, leaving it out.`,
+		`Wrote 1 series to`,
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("output misses %q:\n%s", want, output)
+		}
+	}
+	if err := runner.Discover(t.Context(), 28, 28, path); err == nil || !strings.Contains(err.Error(), "exists") {
+		t.Fatalf("Discover() error = %v, want existing file error", err)
+	}
+}
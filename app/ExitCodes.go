@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+// Process exit codes, documented in the README for wrapper scripts.
+const (
+	ExitOK                 = 0
+	ExitFailure            = 1
+	ExitUsage              = 2
+	ExitConfig             = 3
+	ExitAuthentication     = 4
+	ExitSessionLost        = 5
+	ExitUnavailable        = 6
+	ExitHTTPStatus         = 7
+	ExitRejected           = 8
+	ExitUnexpectedResponse = 9
+	ExitFile               = 10
+	ExitEventsFailed       = 11
+	ExitCheckFailed        = 12
+	// ExitInterrupted follows the shell convention for a process ended by SIGINT.
+	ExitInterrupted = 130
+)
+
+var (
+	// ErrUsage marks invalid command-line arguments.
+	ErrUsage = errors.New("invalid arguments")
+	// ErrEventsFailed marks a -keep-going run in which some events failed.
+	ErrEventsFailed = errors.New("events failed")
+)
+
+func usageError(err error) error {
+	return fmt.Errorf("%w: %w", ErrUsage, err)
+}
+
+// ExitCode maps an error returned by RunApp to the process exit code.
+func ExitCode(err error) int {
+	var (
+		statusErr   *service.HTTPStatusError
+		rejectedErr *service.RejectedError
+		contentErr  *service.UnexpectedContentError
+		fileErr     *service.FileError
+	)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrInterrupted), errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	case errors.Is(err, config.ErrInvalidConfig):
+		return ExitConfig
+	case errors.Is(err, ErrEventsFailed):
+		return ExitEventsFailed
+	case errors.Is(err, ErrCheckFailed):
+		return ExitCheckFailed
+	case errors.Is(err, service.ErrAuthentication):
+		return ExitAuthentication
+	case errors.Is(err, service.ErrSessionLost):
+		return ExitSessionLost
+	case errors.Is(err, service.ErrUnavailable):
+		return ExitUnavailable
+	case errors.As(err, &statusErr):
+		return ExitHTTPStatus
+	case errors.As(err, &rejectedErr):
+		return ExitRejected
+	case errors.Is(err, service.ErrUnexpectedResponse), errors.Is(err, service.ErrResponseTooLarge), errors.As(err, &contentErr):
+		return ExitUnexpectedResponse
+	case errors.As(err, &fileErr):
+		return ExitFile
+	}
+	return ExitFailure
+}
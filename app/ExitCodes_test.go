@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: ExitOK},
+		{name: "other", err: errors.New("boom"), want: ExitFailure},
+		{name: "usage", err: usageError(errors.New("bad flag")), want: ExitUsage},
+		{name: "config", err: fmt.Errorf("%w: %w", config.ErrInvalidConfig, errors.New("missing host")), want: ExitConfig},
+		{name: "login", err: fmt.Errorf("login: %w", service.ErrAuthentication), want: ExitAuthentication},
+		{name: "session", err: fmt.Errorf("upload: %w", service.ErrSessionLost), want: ExitSessionLost},
+		{name: "network", err: &service.RequestError{Operation: "upload", Err: errors.New("connection refused")}, want: ExitUnavailable},
+		{name: "server error", err: &service.HTTPStatusError{Operation: "upload", StatusCode: 502}, want: ExitUnavailable},
+		{name: "client error", err: &service.HTTPStatusError{Operation: "upload", StatusCode: 404}, want: ExitHTTPStatus},
+		{name: "rejected", err: &service.RejectedError{Operation: "upload", Message: "no file"}, want: ExitRejected},
+		{name: "response", err: fmt.Errorf("%w: bad json", service.ErrUnexpectedResponse), want: ExitUnexpectedResponse},
+		{name: "file", err: &service.FileError{Operation: "open", Path: "show.mp3", Err: errors.New("denied")}, want: ExitFile},
+		{name: "keep going", err: fmt.Errorf("1 of 2 %w: %w", ErrEventsFailed, &service.HTTPStatusError{Operation: "upload", StatusCode: 500}), want: ExitEventsFailed},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%v: ExitCode(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+// eventError is a failure that only affects one event; -keep-going continues after it.
+type eventError struct {
+	seriesKey string
+	event     domain.CalCMSEvent
+	step      string
+	err       error
+}
+
+func (e *eventError) Error() string {
+	return e.err.Error()
+}
+
+func (e *eventError) Unwrap() error {
+	return e.err
+}
+
+// newEventError wraps err as a failure of one event. Errors that mean calCMS or the session
+// is gone are returned unchanged, as every remaining event would fail the same way.
+func newEventError(key string, event domain.CalCMSEvent, step string, err error) error {
+	if errors.Is(err, service.ErrSessionLost) || errors.Is(err, service.ErrAuthentication) || errors.Is(err, service.ErrUnavailable) {
+		return err
+	}
+	return &eventError{seriesKey: key, event: event, step: step, err: err}
+}
+
+var failureHeader = []string{"Series", "Event ID", "Event time", "Step", "Error"}
+
+// showFailureSummary lists the failed events of a -keep-going run.
+func (r *Runner) showFailureSummary(failures []*eventError) {
+	rows := make([][]string, 0, len(failures))
+	for _, failure := range failures {
+		rows = append(rows, []string{failure.seriesKey, fmt.Sprint(failure.event.EventID), r.formatEventStart(failure.event), failure.step, failure.err.Error()})
+	}
+	fmt.Fprintf(r.Output, "Failed events (%d):\r\n", len(failures))
+	writeTable(r.Output, OutputText, "", failureHeader, rows)
+}
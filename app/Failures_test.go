@@ -0,0 +1,82 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+func keepGoingTestRunner(keepGoing bool) (*Runner, *recordingTestService, *bytes.Buffer) {
+	fake := &recordingTestService{failEvents: map[int]error{11: errors.New("calCMS rejected upload: event locked")}}
+	runner := testRunner(fake)
+	output := &bytes.Buffer{}
+	runner.Output = output
+	runner.KeepGoing = keepGoing
+	runner.Plan.Series = map[string]domain.SeriesPlan{
+		"a-show": {SeriesInfo: domain.SeriesInfo{SeriesID: 1, FileToUpload: "a.stream"}, Events: []domain.CalCMSEvent{
+			{EventID: 10, Skey: "a-show"}, {EventID: 11, Skey: "a-show", StartDateTime: "2026-07-22T06:00:00"}, {EventID: 12, Skey: "a-show"},
+		}},
+		"b-show": {SeriesInfo: domain.SeriesInfo{SeriesID: 2, FileToUpload: "b.stream"}, Events: []domain.CalCMSEvent{{EventID: 20, Skey: "b-show"}}},
+	}
+	return runner, fake, output
+}
+
+func TestUploadStopsAtFirstFailureByDefault(t *testing.T) {
+	runner, fake, _ := keepGoingTestRunner(false)
+	if err := runner.uploadFilesToCalCMS(t.Context()); err == nil || !strings.Contains(err.Error(), "event locked") {
+		t.Fatalf("error = %v, want the upload failure", err)
+	}
+	if fake.uploadCalls != 2 || runner.Plan.Result(20).Outcome != domain.OutcomePlanned {
+		t.Fatalf("upload calls = %d, event 20 = %v; the run should stop at event 11", fake.uploadCalls, runner.Plan.Result(20).Outcome)
+	}
+}
+
+func TestKeepGoingContinuesAndSummarizes(t *testing.T) {
+	runner, fake, output := keepGoingTestRunner(true)
+	err := runner.uploadFilesToCalCMS(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 events failed") {
+		t.Fatalf("error = %v, want a failure count", err)
+	}
+	var failure *eventError
+	if !errors.As(err, &failure) || failure.event.EventID != 11 {
+		t.Fatalf("error does not wrap the failed event: %v", err)
+	}
+	if fake.uploadCalls != 4 {
+		t.Fatalf("upload calls = %d, want 4", fake.uploadCalls)
+	}
+	for id, want := range map[int]domain.UploadOutcome{10: domain.OutcomeUploaded, 11: domain.OutcomeFailed, 12: domain.OutcomeUploaded, 20: domain.OutcomeUploaded} {
+		if got := runner.Plan.Result(id).Outcome; got != want {
+			t.Fatalf("event %d = %v, want %v", id, got, want)
+		}
+	}
+	for _, want := range []string{"Event 11 failed, continuing:", "Failed events (1):", "Series  Event ID  Event time", "a-show  11        2026-07-22 06:00 UTC  upload"} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("output misses %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestKeepGoingStopsWhenSessionIsLost(t *testing.T) {
+	runner, fake, output := keepGoingTestRunner(true)
+	fake.failEvents[12] = fmt.Errorf("%w: upload was redirected to \"/login\"", service.ErrSessionLost)
+	err := runner.uploadFilesToCalCMS(t.Context())
+	if !errors.Is(err, service.ErrSessionLost) || !strings.Contains(err.Error(), "1 earlier events failed") || !strings.Contains(err.Error(), "event locked") {
+		t.Fatalf("error = %v, want the lost session and the earlier failure", err)
+	}
+	if code := ExitCode(err); code != ExitSessionLost {
+		t.Fatalf("ExitCode() = %d, want %d", code, ExitSessionLost)
+	}
+	if fake.uploadCalls != 3 || runner.Plan.Result(20).Outcome != domain.OutcomePlanned {
+		t.Fatalf("upload calls = %d, event 20 = %v; the run should stop at event 12", fake.uploadCalls, runner.Plan.Result(20).Outcome)
+	}
+	for _, want := range []string{"Event 11 failed, continuing:", "Failed events (1):"} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("output misses %q:\n%s", want, output)
+		}
+	}
+}
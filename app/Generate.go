@@ -0,0 +1,95 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+)
+
+const generateStreamsCommand = "generate-streams"
+
+// runGenerateStreams writes or refreshes the .stream files described by STREAM_RELAYS.
+func runGenerateStreams(args []string, output io.Writer) error {
+	flags := flag.NewFlagSet(os.Args[0]+" "+generateStreamsCommand, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	envFile := flags.String("config.file", ".env", "Specify location of config file. Default is .env")
+	check := flags.Bool("check", false, "Only report outdated stream files without writing them")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usageError(err)
+	}
+
+	var cfg config.AppConfig
+	if err := config.InitRelayConfig(*envFile, &cfg); err != nil {
+		return err
+	}
+	return generateStreamFiles(&cfg, *check, output)
+}
+
+// generateStreamFiles renders every configured relay and writes the files that changed.
+// In check mode nothing is written and outdated files are reported as an error.
+func generateStreamFiles(cfg *config.AppConfig, check bool, output io.Writer) error {
+	streams := cfg.Relays.Streams.StreamFiles()
+	if len(streams) == 0 {
+		return fmt.Errorf("STREAM_RELAYS must contain at least one entry")
+	}
+	outdated := 0
+	for _, stream := range streams {
+		path := cfg.RelayFilePath(stream)
+		content := stream.Render()
+		current, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("read stream file %q: %w", path, err)
+		}
+		if err == nil && bytes.Equal(current, content) {
+			fmt.Fprintf(output, "Stream file \"%v\" is up to date.\r\n", path)
+			continue
+		}
+		if check {
+			outdated++
+			fmt.Fprintf(output, "Stream file \"%v\" is out of date.\r\n", path)
+			continue
+		}
+		if err := writeFileAtomic(path, content); err != nil {
+			return fmt.Errorf("write stream file %q: %w", path, err)
+		}
+		fmt.Fprintf(output, "Wrote stream file \"%v\" for %v.\r\n", path, stream.URL)
+	}
+	if outdated > 0 {
+		return fmt.Errorf("%d stream files are out of date", outdated)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces a file without leaving a partially written version behind.
+func writeFileAtomic(path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+)
+
+func TestGenerateStreamFilesWritesAndChecks(t *testing.T) {
+	cfg := config.AppConfig{}
+	cfg.Relays.ResolvedDir = filepath.Join(t.TempDir(), "uploadfiles")
+	cfg.Relays.Streams = config.StreamRelays{"radiozett": "https://zett-stream.de:8000/zett_uebergabe.mp3"}
+	path := filepath.Join(cfg.Relays.ResolvedDir, "radiozett.stream")
+
+	if err := generateStreamFiles(&cfg, true, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "out of date") {
+		t.Fatalf("check before generating: error = %v, want out of date", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("check mode wrote %q", path)
+	}
+	if err := generateStreamFiles(&cfg, false, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Stream ID for mAirlist: ++radiozett++\nhttps://zett-stream.de:8000/zett_uebergabe.mp3\n"
+	if string(got) != want {
+		t.Fatalf("stream file = %q, want %q", got, want)
+	}
+	if err := generateStreamFiles(&cfg, true, &bytes.Buffer{}); err != nil {
+		t.Fatalf("check after generating: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ErrInterrupted marks a run that was stopped by a signal before all events were processed.
+var ErrInterrupted = errors.New("interrupted")
+
+// withInterrupts runs fn with a context for the runner that reacts to SIGINT and SIGTERM.
+// While the runner changes calCMS, the first signal lets the current event finish and stops
+// before the next one, and a second signal cancels the context, which aborts the current
+// request. At any other time the first signal cancels the context right away.
+func withInterrupts(r *Runner, fn func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		stopping := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				if !stopping && r.RequestStop() {
+					stopping = true
+					fmt.Fprint(r.Output, "\r\nStopping after the current event, interrupt again to abort it.\r\n")
+					continue
+				}
+				cancel()
+				return
+			}
+		}
+	}()
+	return fn(ctx)
+}
+
+// RequestStop asks a running upload or rollback to stop before its next event. It reports
+// false if the runner is not changing calCMS, so there is no event to finish first.
+func (r *Runner) RequestStop() bool {
+	r.stopRequested.Store(true)
+	return r.changing.Load()
+}
+
+// stopping reports whether the run should end before the next event.
+func (r *Runner) stopping(ctx context.Context) bool {
+	return ctx.Err() != nil || r.stopRequested.Load()
+}
+
+// stopBeforeEvent reports a stopped run and returns the error that ends it.
+func (r *Runner) stopBeforeEvent(eventID, done, total int) error {
+	fmt.Fprintf(r.Output, "Stopped before event %d: %d of %d events were processed.\r\n", eventID, done, total)
+	return fmt.Errorf("%w: %d of %d events were not processed", ErrInterrupted, total-done, total)
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func interruptTestRunner(fake *recordingTestService) (*Runner, *bytes.Buffer) {
+	runner := testRunner(fake)
+	runner.Plan.Series["show"] = domain.SeriesPlan{
+		SeriesInfo: domain.SeriesInfo{SeriesID: 99, FileToUpload: "show.stream"},
+		Events:     []domain.CalCMSEvent{{EventID: 42, Skey: "show"}, {EventID: 43, Skey: "show"}, {EventID: 44, Skey: "show"}},
+	}
+	output := &bytes.Buffer{}
+	runner.Output = output
+	return runner, output
+}
+
+func TestRequestStopFinishesCurrentEvent(t *testing.T) {
+	fake := &recordingTestService{}
+	runner, output := interruptTestRunner(fake)
+	if runner.RequestStop() {
+		t.Fatal("RequestStop() reported a running upload before the upload started")
+	}
+	runner.stopRequested.Store(false)
+	fake.onUpload = func(int) {
+		if !runner.RequestStop() {
+			t.Error("RequestStop() did not report the running upload")
+		}
+	}
+	err := runner.uploadFilesToCalCMS(t.Context())
+	if !errors.Is(err, ErrInterrupted) || ExitCode(err) != ExitInterrupted {
+		t.Fatalf("uploadFilesToCalCMS() error = %v, want ErrInterrupted", err)
+	}
+	if fake.uploadCalls != 1 {
+		t.Fatalf("upload calls = %d, want 1", fake.uploadCalls)
+	}
+	if !strings.Contains(output.String(), "Stopped before event 43: 1 of 3 events were processed.") {
+		t.Fatalf("output = %s", output)
+	}
+	if result := runner.Plan.Result(42); result.Outcome != domain.OutcomeUploaded {
+		t.Fatalf("event 42 = %+v, want uploaded", result)
+	}
+	if result := runner.Plan.Result(43); result.Outcome != domain.OutcomePlanned {
+		t.Fatalf("event 43 = %+v, want planned", result)
+	}
+}
+
+func TestCancelAbortsCurrentUpload(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	fake := &recordingTestService{onUpload: func(int) { cancel() }}
+	runner, _ := interruptTestRunner(fake)
+	runner.KeepGoing = true
+	err := runner.uploadFilesToCalCMS(ctx)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, ErrInterrupted) || ExitCode(err) != ExitInterrupted {
+		t.Fatalf("uploadFilesToCalCMS() error = %v, want cancellation", err)
+	}
+	if fake.uploadCalls != 1 {
+		t.Fatalf("upload calls = %d, want 1", fake.uploadCalls)
+	}
+	if result := runner.Plan.Result(42); result.Outcome != domain.OutcomeFailed {
+		t.Fatalf("event 42 = %+v, want failed", result)
+	}
+}
+
+func TestReadLineReturnsOnCancel(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	runner := NewRunner(config.AppConfig{}, reader, &bytes.Buffer{}, nil)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := runner.readLine(ctx, "read confirmation"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("readLine() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+const (
+	progressBarWidth      = 20
+	terminalProgressEvery = 200 * time.Millisecond
+	logProgressEvery      = 10 * time.Second
+)
+
+// progressReporter renders upload progress as a terminal progress bar or as periodic log lines.
+type progressReporter struct {
+	mu         sync.Mutex
+	output     io.Writer
+	now        func() time.Time
+	terminal   bool
+	interval   time.Duration
+	totalBytes int64
+	doneBytes  int64
+	events     int
+	event      int
+	eventID    int
+	eventSent  int64
+	eventTotal int64
+	started    time.Time
+	lastRender time.Time
+	rendered   bool
+}
+
+func newProgressReporter(output io.Writer, now func() time.Time) *progressReporter {
+	p := &progressReporter{output: output, now: now, terminal: isTerminal(output), interval: logProgressEvery}
+	if p.terminal {
+		p.interval = terminalProgressEvery
+	}
+	return p
+}
+
+// isTerminal reports whether output is an interactive character device.
+func isTerminal(output io.Writer) bool {
+	file, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// start resets the reporter for a run with the given number of uploads and bytes.
+func (p *progressReporter) start(events int, totalBytes int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events, p.totalBytes, p.doneBytes, p.event = events, totalBytes, 0, 0
+	p.started = p.now()
+}
+
+// startEvent begins reporting a new upload.
+func (p *progressReporter) startEvent(eventID int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.event++
+	p.eventID, p.eventSent, p.eventTotal = eventID, 0, 0
+	p.lastRender = time.Time{}
+	if !p.terminal {
+		// Log lines only appear for uploads that take longer than the interval.
+		p.lastRender = p.now()
+	}
+}
+
+// skipEvent removes an upload that will not happen from the overall total.
+func (p *progressReporter) skipEvent(size int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events--
+	p.totalBytes -= size
+}
+
+// update is the service.ProgressFunc of the runner.
+func (p *progressReporter) update(progress service.UploadProgress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.eventSent, p.eventTotal = progress.Sent, progress.Total
+	now := p.now()
+	if progress.Sent < progress.Total && now.Sub(p.lastRender) < p.interval {
+		return
+	}
+	if !p.terminal && progress.Sent == progress.Total && !p.rendered {
+		return
+	}
+	p.lastRender = now
+	p.render(now)
+}
+
+// finishEvent adds the completed upload to the overall progress and ends the terminal line.
+func (p *progressReporter) finishEvent() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.doneBytes += p.eventTotal
+	if p.terminal && p.rendered {
+		fmt.Fprint(p.output, "\r\n")
+	}
+	p.rendered = false
+}
+
+func (p *progressReporter) render(now time.Time) {
+	overall := p.doneBytes + p.eventSent
+	total := max(p.totalBytes, overall)
+	elapsed := now.Sub(p.started)
+	rate, eta := "-", "-"
+	if elapsed > 0 && overall > 0 {
+		bytesPerSecond := float64(overall) / elapsed.Seconds()
+		rate = config.ByteSize(bytesPerSecond).String() + "/s"
+		eta = time.Duration(float64(total-overall) / bytesPerSecond * float64(time.Second)).Round(time.Second).String()
+	}
+	status := fmt.Sprintf("event %d/%d (ID %d) %v/%v, total %v/%v, %v, ETA %v",
+		p.event, p.events, p.eventID, config.ByteSize(p.eventSent), config.ByteSize(p.eventTotal),
+		config.ByteSize(overall), config.ByteSize(total), rate, eta)
+	if p.terminal {
+		fmt.Fprintf(p.output, "\r%v %v\x1b[K", progressBar(overall, total), status)
+	} else {
+		fmt.Fprintf(p.output, "Upload progress: %v\r\n", status)
+	}
+	p.rendered = true
+}
+
+func progressBar(done, total int64) string {
+	filled := progressBarWidth
+	if total > 0 {
+		filled = int(done * progressBarWidth / total)
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled) + "]"
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+func TestProgressReporterLogsPeriodicallyWithoutTerminal(t *testing.T) {
+	now := time.Date(2026, time.July, 21, 12, 0, 0, 0, time.UTC)
+	output := &bytes.Buffer{}
+	p := newProgressReporter(output, func() time.Time { return now })
+	p.start(2, 4<<20)
+	p.startEvent(42)
+
+	now = now.Add(time.Second)
+	p.update(service.UploadProgress{EventID: 42, Sent: 512 << 10, Total: 2 << 20})
+	if output.Len() != 0 {
+		t.Fatalf("logged before the interval elapsed: %q", output)
+	}
+	now = now.Add(logProgressEvery)
+	p.update(service.UploadProgress{EventID: 42, Sent: 1 << 20, Total: 2 << 20})
+	want := "Upload progress: event 1/2 (ID 42) 1.0MiB/2.0MiB, total 1.0MiB/4.0MiB, 93.1KiB/s, ETA 33s\r\n"
+	if output.String() != want {
+		t.Fatalf("output = %q, want %q", output, want)
+	}
+	if strings.Contains(output.String(), "\x1b") {
+		t.Fatal("log output contains terminal escape sequences")
+	}
+	p.update(service.UploadProgress{EventID: 42, Sent: 2 << 20, Total: 2 << 20})
+	p.finishEvent()
+	if !strings.HasSuffix(output.String(), "total 2.0MiB/4.0MiB, 186.2KiB/s, ETA 11s\r\n") {
+		t.Fatalf("final line = %q", output)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	if got := progressBar(1, 4); got != "[#####...............]" {
+		t.Fatalf("progressBar() = %q", got)
+	}
+}
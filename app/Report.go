@@ -0,0 +1,188 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+// Report formats for -output.
+const (
+	OutputText     = "text"
+	OutputJSON     = "json"
+	OutputCSV      = "csv"
+	OutputMarkdown = "markdown"
+)
+
+var outputFormats = []string{OutputText, OutputJSON, OutputCSV, OutputMarkdown}
+
+// Report kinds: the preview before confirming and the report after the run.
+const (
+	reportPlan   = "plan"
+	reportResult = "result"
+)
+
+// validateOutputFormat checks a -output value.
+func validateOutputFormat(format string) error {
+	if !slices.Contains(outputFormats, format) {
+		return fmt.Errorf("output format %q is not one of %v", format, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// reportRow describes one event of the plan and what the run did with it.
+type reportRow struct {
+	Series    string                `json:"series"`
+	SeriesID  int                   `json:"series_id"`
+	EventID   int                   `json:"event_id"`
+	EventTime string                `json:"event_time"`
+	Title     string                `json:"title"`
+	File      string                `json:"file"`
+	Recording domain.RecordingState `json:"recording_before"`
+	Action    domain.UploadOutcome  `json:"action"`
+	Reason    string                `json:"reason,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// runReport is the plan preview or the run report.
+type runReport struct {
+	Kind string      `json:"report"`
+	From string      `json:"from"`
+	Till string      `json:"till"`
+	Rows []reportRow `json:"rows"`
+}
+
+var reportHeader = []string{"Series", "Series ID", "Event ID", "Event time", "Title", "File", "Recording before", "Action", "Reason", "Error"}
+
+func (row reportRow) fields() []string {
+	return []string{row.Series, strconv.Itoa(row.SeriesID), strconv.Itoa(row.EventID), row.EventTime, row.Title, row.File, string(row.Recording), string(row.Action), row.Reason, row.Error}
+}
+
+// buildReport collects the rows of the plan in display order. JSON and CSV use RFC 3339 times.
+func (r *Runner) buildReport(kind, format string) runReport {
+	timeFormat := eventTimeFormat
+	if format == OutputJSON || format == OutputCSV {
+		timeFormat = time.RFC3339
+	}
+	report := runReport{Kind: kind, From: r.Plan.From.Format(timeFormat), Till: r.Plan.Till.Format(timeFormat), Rows: []reportRow{}}
+	for _, key := range r.sortedSeriesKeys() {
+		data := r.Plan.Series[key]
+		for _, event := range data.Events {
+			eventTime := event.StartDateTime
+			if start, err := event.Start(r.Cfg.TimeLocation()); err == nil {
+				eventTime = start.Format(timeFormat)
+			}
+			result := r.Plan.Result(event.EventID)
+			row := reportRow{
+				Series:    key,
+				SeriesID:  data.SeriesID,
+				EventID:   event.EventID,
+				EventTime: eventTime,
+				Title:     event.Title,
+				File:      data.FileToUpload,
+				Recording: result.Recording,
+				Action:    result.Outcome,
+			}
+			if row.Recording == "" {
+				row.Recording = domain.RecordingUnknown
+			}
+			if result.Outcome == domain.OutcomeFailed {
+				row.Error = result.Reason
+			} else {
+				row.Reason = result.Reason
+			}
+			if scheduled, mismatch := r.durationMismatch(data, event); mismatch && row.Reason == "" {
+				row.Reason = fmt.Sprintf("file plays %v, event is scheduled for %v", data.AudioDuration, scheduled)
+			}
+			report.Rows = append(report.Rows, row)
+		}
+	}
+	return report
+}
+
+// writeReport renders a report in one of the output formats.
+func writeReport(w io.Writer, format string, report runReport) error {
+	if format == OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	title := ""
+	if format == OutputMarkdown {
+		title = "Planned uploads"
+		if report.Kind == reportResult {
+			title = "Upload report"
+		}
+		title = fmt.Sprintf("%v %v to %v", title, report.From, report.Till)
+	}
+	rows := make([][]string, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		rows = append(rows, row.fields())
+	}
+	return writeTable(w, format, title, reportHeader, rows)
+}
+
+// writeTable renders rows as CSV, as a Markdown table with an optional heading, or as aligned text.
+func writeTable(w io.Writer, format, title string, header []string, rows [][]string) error {
+	switch format {
+	case OutputCSV:
+		writer := csv.NewWriter(w)
+		writer.Write(header)
+		writer.WriteAll(rows)
+		return writer.Error()
+	case OutputMarkdown:
+		var b strings.Builder
+		if title != "" {
+			fmt.Fprintf(&b, "## %v\n\n", title)
+		}
+		fmt.Fprintf(&b, "| %v |\n", strings.Join(header, " | "))
+		fmt.Fprintf(&b, "|%v\n", strings.Repeat(" --- |", len(header)))
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, field := range row {
+				cells[i] = escapeMarkdownCell(field)
+			}
+			fmt.Fprintf(&b, "| %v |\n", strings.Join(cells, " | "))
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	default:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+}
+
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// writeRunReport writes the report after the run to Report, or to Output if no report writer is set.
+func (r *Runner) writeRunReport() error {
+	w := r.Report
+	if w == nil {
+		w = r.Output
+	}
+	if r.OutputFormat == OutputText || r.OutputFormat == "" {
+		if r.eventCount() == 0 {
+			return nil
+		}
+		fmt.Fprint(w, "Run report:\r\n")
+	}
+	if err := writeReport(w, r.OutputFormat, r.buildReport(reportResult, r.OutputFormat)); err != nil {
+		return fmt.Errorf("write run report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func reportTestRunner() *Runner {
+	runner := testRunner(&recordingTestService{})
+	runner.setDayRange(time.Date(2026, time.July, 22, 0, 0, 0, 0, time.UTC), time.Date(2026, time.July, 23, 0, 0, 0, 0, time.UTC))
+	runner.Plan.Series = map[string]domain.SeriesPlan{
+		"show": {SeriesInfo: domain.SeriesInfo{SeriesID: 99, FileToUpload: "show.stream"}, Events: []domain.CalCMSEvent{
+			{EventID: 42, Skey: "show", Title: "Show | Live", StartDateTime: "2026-07-22T08:00:00"},
+			{EventID: 43, Skey: "show", Title: "Show", StartDateTime: "2026-07-23T08:00:00"},
+			{EventID: 44, Skey: "show", Title: "Show"},
+		}},
+	}
+	runner.Plan.Results[42] = domain.NewEventResult(domain.OutcomeUploaded, "", true)
+	runner.Plan.Results[43] = domain.NewEventResult(domain.OutcomeFailed, "upload rejected", false)
+	return runner
+}
+
+func TestJSONReport(t *testing.T) {
+	runner := reportTestRunner()
+	var out bytes.Buffer
+	if err := writeReport(&out, OutputJSON, runner.buildReport(reportResult, OutputJSON)); err != nil {
+		t.Fatal(err)
+	}
+	var report runReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, out.String())
+	}
+	if report.Kind != reportResult || report.From != "2026-07-22T00:00:00Z" || report.Till != "2026-07-24T00:00:00Z" || len(report.Rows) != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	want := []reportRow{
+		{Series: "show", SeriesID: 99, EventID: 42, EventTime: "2026-07-22T08:00:00Z", Title: "Show | Live", File: "show.stream", Recording: domain.RecordingActive, Action: domain.OutcomeUploaded},
+		{Series: "show", SeriesID: 99, EventID: 43, EventTime: "2026-07-23T08:00:00Z", Title: "Show", File: "show.stream", Recording: domain.RecordingNone, Action: domain.OutcomeFailed, Error: "upload rejected"},
+		{Series: "show", SeriesID: 99, EventID: 44, Title: "Show", File: "show.stream", Recording: domain.RecordingUnknown, Action: domain.OutcomePlanned},
+	}
+	for i, row := range report.Rows {
+		if row != want[i] {
+			t.Fatalf("row %d = %+v, want %+v", i, row, want[i])
+		}
+	}
+}
+
+func TestCSVReport(t *testing.T) {
+	runner := reportTestRunner()
+	var out bytes.Buffer
+	if err := writeReport(&out, OutputCSV, runner.buildReport(reportResult, OutputCSV)); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || strings.Join(records[0], ",") != strings.Join(reportHeader, ",") {
+		t.Fatalf("records = %v", records)
+	}
+	if got := strings.Join(records[2], ","); got != "show,99,43,2026-07-23T08:00:00Z,Show,show.stream,none,failed,,upload rejected" {
+		t.Fatalf("second row = %s", got)
+	}
+}
+
+func TestMarkdownReport(t *testing.T) {
+	runner := reportTestRunner()
+	var out bytes.Buffer
+	if err := writeReport(&out, OutputMarkdown, runner.buildReport(reportPlan, OutputMarkdown)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Planned uploads 2026-07-22 00:00 UTC to 2026-07-24 00:00 UTC\n",
+		"| Series | Series ID | Event ID | Event time | Title | File | Recording before | Action | Reason | Error |\n",
+		"| show | 99 | 42 | 2026-07-22 08:00 UTC | Show \\| Live | show.stream | active | uploaded |  |  |\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("markdown misses %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunWritesReportToReportWriter(t *testing.T) {
+	fake := &recordingTestService{events: []domain.CalCMSEvent{{EventID: 42, Skey: "show", StartDateTime: "2026-07-21T20:00:00"}}}
+	runner := testRunner(fake)
+	var report bytes.Buffer
+	runner.Report = &report
+	runner.OutputFormat = OutputJSON
+	runner.Input = bufio.NewScanner(strings.NewReader("\n\ny\n"))
+	if err := runner.Run(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	var parsed runReport
+	if err := json.Unmarshal(report.Bytes(), &parsed); err != nil {
+		t.Fatalf("run report is not JSON: %v\n%s", err, report.String())
+	}
+	if len(parsed.Rows) != 1 || parsed.Rows[0].Action != domain.OutcomeUploaded || parsed.Rows[0].Recording != domain.RecordingNone {
+		t.Fatalf("report rows = %+v", parsed.Rows)
+	}
+}
+
+func TestRunWritesNoReportWhenAborted(t *testing.T) {
+	fake := &recordingTestService{events: []domain.CalCMSEvent{{EventID: 42, Skey: "show", StartDateTime: "2026-07-21T20:00:00"}}}
+	runner := testRunner(fake)
+	var report, output bytes.Buffer
+	runner.Report = &report
+	runner.Output = &output
+	runner.Input = bufio.NewScanner(strings.NewReader("\n\nn\n"))
+	if err := runner.Run(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if report.Len() != 0 {
+		t.Fatalf("report written after abort:\n%s", report.String())
+	}
+	if !strings.HasSuffix(output.String(), "Aborting...\r\n") {
+		t.Fatalf("output does not end with a terminated abort line:\n%q", output.String())
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range outputFormats {
+		if err := validateOutputFormat(format); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := validateOutputFormat("xml"); err == nil {
+		t.Fatal("validateOutputFormat accepted xml")
+	}
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+const rollbackCommand = "rollback"
+
+// runRollback undoes the uploads of an earlier run recorded in the audit log.
+func runRollback(args []string) error {
+	flags := flag.NewFlagSet(os.Args[0]+" "+rollbackCommand, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	envFile := flags.String("config.file", ".env", "Specify location of config file. Default is .env")
+	dryRun := flags.Bool("dry-run", false, "Only show what the rollback would change")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usageError(err)
+	}
+	if flags.NArg() != 1 {
+		return usageError(fmt.Errorf("usage: %v %v [-config.file FILE] [-dry-run] RUN-ID", os.Args[0], rollbackCommand))
+	}
+
+	var cfg config.AppConfig
+	if err := config.InitCalCmsConfig(*envFile, &cfg); err != nil {
+		return err
+	}
+	runner := NewRunner(cfg, os.Stdin, os.Stdout, time.Now)
+	runner.Service = service.NewCalCmsService(&runner.Cfg)
+	return withInterrupts(runner, func(ctx context.Context) error {
+		return runner.Rollback(ctx, flags.Arg(0), *dryRun)
+	})
+}
+
+// rollbackEntries returns the successful uploads of a run, in the order they were made.
+func rollbackEntries(entries []service.AuditEntry) []service.AuditEntry {
+	var uploads []service.AuditEntry
+	for _, entry := range entries {
+		if (entry.Action == service.AuditUpload || entry.Action == "") && entry.Result == domain.OutcomeUploaded {
+			uploads = append(uploads, entry)
+		}
+	}
+	return uploads
+}
+
+// Rollback deletes the recordings a run uploaded, so that calCMS makes the recordings that
+// were active before the run active again. It shows the planned changes first and asks for
+// confirmation; with dryRun it stops after the preview.
+func (r *Runner) Rollback(ctx context.Context, runID string, dryRun bool) error {
+	entries, err := service.ReadAuditLog(r.Cfg.Audit.ResolvedFile, service.AuditFilter{RunID: runID})
+	if err != nil {
+		return err
+	}
+	uploads := rollbackEntries(entries)
+	if len(uploads) == 0 {
+		return fmt.Errorf("the audit log has no uploads for run %q", runID)
+	}
+	r.showRollbackPlan(runID, uploads)
+	if dryRun {
+		return nil
+	}
+	if !slices.ContainsFunc(uploads, func(upload service.AuditEntry) bool { return upload.UploadedPath != "" }) {
+		return fmt.Errorf("no upload of run %q can be rolled back", runID)
+	}
+	fmt.Fprint(r.Output, "Confirm with \"y\" to roll back: ")
+	decision, err := r.readLine(ctx, "read confirmation")
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(decision), "y") {
+		fmt.Fprint(r.Output, "Aborting...\r\n")
+		return nil
+	}
+
+	r.RunID = newRunID(r.Now())
+	if err := r.openAudit(); err != nil {
+		return err
+	}
+	defer r.closeAudit()
+	if err := r.Service.Login(ctx, r.Cfg.CalCms.CmsUser, r.Cfg.CalCms.CmsPass); err != nil {
+		return fmt.Errorf("log in to calCMS: %w", err)
+	}
+	r.changing.Store(true)
+	defer r.changing.Store(false)
+	var failures []error
+	for i, upload := range uploads {
+		if upload.UploadedPath == "" {
+			continue
+		}
+		if r.stopping(ctx) {
+			failures = append(failures, r.stopBeforeEvent(upload.EventID, i, len(uploads)))
+			break
+		}
+		if err := r.rollbackUpload(ctx, runID, upload); err != nil {
+			fmt.Fprintf(r.Output, "Event %d: %v\r\n", upload.EventID, err)
+			failures = append(failures, fmt.Errorf("roll back event %d: %w", upload.EventID, err))
+		}
+	}
+	return errors.Join(failures...)
+}
+
+func (r *Runner) showRollbackPlan(runID string, uploads []service.AuditEntry) {
+	fmt.Fprintf(r.Output, "Rollback of run %v:\r\n", runID)
+	for _, upload := range uploads {
+		prefix := fmt.Sprintf("  Event %d (\"%v\")", upload.EventID, upload.SeriesKey)
+		switch {
+		case upload.UploadedPath == "":
+			fmt.Fprintf(r.Output, "%v: cannot roll back, the audit log does not name the uploaded recording.\r\n", prefix)
+		case upload.PreviousPath != "":
+			fmt.Fprintf(r.Output, "%v: delete \"%v\", \"%v\" becomes active again.\r\n", prefix, upload.UploadedPath, upload.PreviousPath)
+		case upload.PreviousRecording == domain.RecordingActive:
+			fmt.Fprintf(r.Output, "%v: delete \"%v\"; the previous recording is not known, check which recording becomes active.\r\n", prefix, upload.UploadedPath)
+		default:
+			fmt.Fprintf(r.Output, "%v: delete \"%v\", the event had no active recording before.\r\n", prefix, upload.UploadedPath)
+		}
+	}
+}
+
+// rollbackUpload deletes one uploaded recording, records the deletion in the audit log,
+// activates the previous recording again, and checks that it is active. It refuses to
+// delete if the previous recording is gone, as deleting cannot be undone.
+func (r *Runner) rollbackUpload(ctx context.Context, runID string, upload service.AuditEntry) error {
+	recordings, err := r.Service.Recordings(ctx, upload.EventID, upload.SeriesID)
+	if err != nil {
+		return fmt.Errorf("list recordings: %w", err)
+	}
+	if !hasRecordingPath(recordings, upload.UploadedPath) {
+		fmt.Fprintf(r.Output, "Event %d: \"%v\" is already gone, nothing to do.\r\n", upload.EventID, upload.UploadedPath)
+		return nil
+	}
+	if upload.PreviousPath != "" && !hasRecordingPath(recordings, upload.PreviousPath) {
+		return fmt.Errorf("not deleting \"%v\": the previous recording \"%v\" is gone and cannot be activated again", upload.UploadedPath, upload.PreviousPath)
+	}
+	active := service.ActiveRecording(recordings)
+	deleteErr := r.Service.DeleteRecording(ctx, upload.EventID, upload.SeriesID, upload.UploadedPath)
+	if err := r.recordDeletion(runID, upload, active, deleteErr); err != nil {
+		return errors.Join(deleteErr, err)
+	}
+	if deleteErr != nil {
+		return deleteErr
+	}
+	if upload.PreviousPath == "" {
+		fmt.Fprintf(r.Output, "Event %d: deleted \"%v\".\r\n", upload.EventID, upload.UploadedPath)
+		return nil
+	}
+	if err := r.Service.ActivateRecording(ctx, upload.EventID, upload.SeriesID, upload.PreviousPath); err != nil {
+		return fmt.Errorf("deleted \"%v\", but cannot activate the previous recording \"%v\": %w", upload.UploadedPath, upload.PreviousPath, err)
+	}
+	recordings, err = r.Service.Recordings(ctx, upload.EventID, upload.SeriesID)
+	if err != nil {
+		return fmt.Errorf("deleted \"%v\", but cannot check the active recording: %w", upload.UploadedPath, err)
+	}
+	if now := service.ActiveRecording(recordings); now != upload.PreviousPath {
+		return fmt.Errorf("deleted \"%v\", but \"%v\" is active instead of the previous recording \"%v\"", upload.UploadedPath, now, upload.PreviousPath)
+	}
+	fmt.Fprintf(r.Output, "Event %d: deleted \"%v\", \"%v\" is active again.\r\n", upload.EventID, upload.UploadedPath, upload.PreviousPath)
+	return nil
+}
+
+func hasRecordingPath(recordings []service.Recording, path string) bool {
+	for _, recording := range recordings {
+		if recording.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// recordDeletion appends a rollback deletion to the audit log.
+func (r *Runner) recordDeletion(runID string, upload service.AuditEntry, active string, deleteErr error) error {
+	if r.audit == nil {
+		return nil
+	}
+	entry := r.newAuditEntry(service.AuditDelete, upload.SeriesKey, upload.SeriesID, upload.EventID)
+	entry.RollbackOf = runID
+	entry.File = upload.File
+	entry.SHA256 = upload.SHA256
+	entry.PreviousRecording = domain.RecordingNone
+	if active != "" {
+		entry.PreviousRecording = domain.RecordingActive
+	}
+	entry.PreviousPath = active
+	entry.UploadedPath = upload.UploadedPath
+	entry.Result = domain.OutcomeDeleted
+	if deleteErr != nil {
+		entry.Result = domain.OutcomeFailed
+		entry.Error = deleteErr.Error()
+	}
+	return r.audit.Append(entry)
+}
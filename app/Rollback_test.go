@@ -0,0 +1,144 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/service"
+)
+
+// rollbackTestRunner uploads to two events, one with and one without a previous recording.
+func rollbackTestRunner(t *testing.T) (*Runner, *recordingTestService) {
+	t.Helper()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "show.mp3")
+	if err := os.WriteFile(file, []byte("audio"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fake := &recordingTestService{recordings: map[int][]service.Recording{
+		42: {{Path: "older.mp3"}, {Path: "previous.mp3", Active: true}},
+	}}
+	runner := testRunner(fake)
+	runner.Cfg.Audit.ResolvedFile = filepath.Join(dir, "audit.jsonl")
+	runner.RunID = "run-1"
+	runner.Overwrite = true
+	runner.Plan.Series["show"] = domain.SeriesPlan{
+		SeriesInfo: domain.SeriesInfo{SeriesID: 99, FileToUpload: file},
+		Events:     []domain.CalCMSEvent{{EventID: 42, Skey: "show"}, {EventID: 43, Skey: "show"}},
+	}
+	if err := runner.uploadFilesToCalCMS(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	return runner, fake
+}
+
+func TestUploadRecordsRecordingPaths(t *testing.T) {
+	runner, _ := rollbackTestRunner(t)
+	entries, err := service.ReadAuditLog(runner.Cfg.Audit.ResolvedFile, service.AuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("audit entries = %+v", entries)
+	}
+	if entries[0].Action != service.AuditUpload || entries[0].PreviousPath != "previous.mp3" || entries[0].UploadedPath != "upload-42-1.mp3" {
+		t.Fatalf("first entry = %+v", entries[0])
+	}
+	if entries[1].PreviousPath != "" || entries[1].UploadedPath != "upload-43-2.mp3" {
+		t.Fatalf("second entry = %+v", entries[1])
+	}
+}
+
+func TestRollbackRestoresPreviousRecordings(t *testing.T) {
+	runner, fake := rollbackTestRunner(t)
+	output := &bytes.Buffer{}
+	runner.Output = output
+	runner.Input = bufio.NewScanner(strings.NewReader("y\n"))
+	if err := runner.Rollback(t.Context(), "run-1", false); err != nil {
+		t.Fatalf("Rollback() error = %v\n%s", err, output)
+	}
+	if fmt.Sprint(fake.deleted) != "[upload-42-1.mp3 upload-43-2.mp3]" {
+		t.Fatalf("deleted = %v", fake.deleted)
+	}
+	if active := service.ActiveRecording(fake.recordings[42]); active != "previous.mp3" {
+		t.Fatalf("active recording of event 42 = %q, want previous.mp3", active)
+	}
+	for _, want := range []string{
+		`Event 42 ("show"): delete "upload-42-1.mp3", "previous.mp3" becomes active again.`,
+		`Event 43 ("show"): delete "upload-43-2.mp3", the event had no active recording before.`,
+		`Event 42: deleted "upload-42-1.mp3", "previous.mp3" is active again.`,
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("output misses %q:\n%s", want, output)
+		}
+	}
+	deletions, err := service.ReadAuditLog(runner.Cfg.Audit.ResolvedFile, service.AuditFilter{EventID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := deletions[len(deletions)-1]
+	if last.Action != service.AuditDelete || last.RollbackOf != "run-1" || last.RunID == "run-1" || last.Result != domain.OutcomeDeleted || last.UploadedPath != "upload-42-1.mp3" {
+		t.Fatalf("deletion entry = %+v", last)
+	}
+
+	// A second rollback finds nothing left to delete.
+	fake.deleted = nil
+	runner.Input = bufio.NewScanner(strings.NewReader("y\n"))
+	if err := runner.Rollback(t.Context(), "run-1", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.deleted) != 0 || !strings.Contains(output.String(), `"upload-42-1.mp3" is already gone`) {
+		t.Fatalf("deleted = %v\n%s", fake.deleted, output)
+	}
+}
+
+func TestRollbackDryRunChangesNothing(t *testing.T) {
+	runner, fake := rollbackTestRunner(t)
+	loginCalls := fake.loginCalls
+	if err := runner.Rollback(t.Context(), "run-1", true); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.deleted) != 0 || fake.loginCalls != loginCalls {
+		t.Fatalf("dry run deleted %v and logged in %d times", fake.deleted, fake.loginCalls-loginCalls)
+	}
+	if err := runner.Rollback(t.Context(), "run-unknown", true); err == nil {
+		t.Fatal("Rollback() accepted an unknown run")
+	}
+}
+
+func TestRollbackActivatesPreviousRecordingExplicitly(t *testing.T) {
+	runner, fake := rollbackTestRunner(t)
+	fake.recordings[42] = append(fake.recordings[42], service.Recording{Path: "later.mp3"})
+	runner.Output = &bytes.Buffer{}
+	runner.Input = bufio.NewScanner(strings.NewReader("y\n"))
+	if err := runner.Rollback(t.Context(), "run-1", false); err != nil {
+		t.Fatalf("Rollback() error = %v\n%s", err, runner.Output)
+	}
+	if fmt.Sprint(fake.activated) != "[previous.mp3]" {
+		t.Fatalf("activated = %v, want only previous.mp3", fake.activated)
+	}
+	if active := service.ActiveRecording(fake.recordings[42]); active != "previous.mp3" {
+		t.Fatalf("active recording of event 42 = %q, want previous.mp3", active)
+	}
+}
+
+func TestRollbackRefusesWhenPreviousRecordingIsGone(t *testing.T) {
+	runner, fake := rollbackTestRunner(t)
+	fake.recordings[42] = slices.DeleteFunc(fake.recordings[42], func(r service.Recording) bool { return r.Path == "previous.mp3" })
+	runner.Output = &bytes.Buffer{}
+	runner.Input = bufio.NewScanner(strings.NewReader("y\n"))
+	err := runner.Rollback(t.Context(), "run-1", false)
+	if err == nil || !strings.Contains(err.Error(), `the previous recording "previous.mp3" is gone`) {
+		t.Fatalf("Rollback() error = %v, want refusal for event 42", err)
+	}
+	if fmt.Sprint(fake.deleted) != "[upload-43-2.mp3]" {
+		t.Fatalf("deleted = %v, want only the upload of event 43", fake.deleted)
+	}
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+// selectionEntry is one numbered event in the interactive review.
+type selectionEntry struct {
+	seriesKey string
+	event     domain.CalCMSEvent
+}
+
+// selectionSeries is one lettered series in the interactive review.
+type selectionSeries struct {
+	label string
+	key   string
+	// entries are the indexes of the series events in the entry list.
+	entries []int
+}
+
+// eventSelection holds the state of the interactive review. Events are numbered from 1
+// in the displayed order, series are labelled with letters.
+type eventSelection struct {
+	entries  []selectionEntry
+	series   []selectionSeries
+	excluded map[int]bool
+}
+
+// selectionCommands are the words of the review prompt, which series labels must not take.
+var selectionCommands = map[string]bool{"y": true, "q": true, "all": true, "none": true}
+
+// seriesLabelLetters leaves out Q and Y, so no single-letter label is a command.
+const seriesLabelLetters = "ABCDEFGHIJKLMNOPRSTUVWXZ"
+
+func (r *Runner) newEventSelection() *eventSelection {
+	selection := &eventSelection{excluded: make(map[int]bool)}
+	labelIndex := 0
+	for _, key := range r.sortedSeriesKeys() {
+		label := seriesLabel(labelIndex)
+		for selectionCommands[strings.ToLower(label)] {
+			labelIndex++
+			label = seriesLabel(labelIndex)
+		}
+		labelIndex++
+		series := selectionSeries{label: label, key: key}
+		for _, event := range r.Plan.Series[key].Events {
+			series.entries = append(series.entries, len(selection.entries))
+			selection.entries = append(selection.entries, selectionEntry{seriesKey: key, event: event})
+		}
+		selection.series = append(selection.series, series)
+	}
+	return selection
+}
+
+// seriesLabel returns spreadsheet-style letters without Q and Y: A .. Z, AA, AB, ...
+func seriesLabel(index int) string {
+	base := len(seriesLabelLetters)
+	label := ""
+	for index >= 0 {
+		label = string(seriesLabelLetters[index%base]) + label
+		index = index/base - 1
+	}
+	return label
+}
+
+// selectEvents lets the user review the planned events, toggle single events or whole
+// series and confirm the selection. Events left out are removed from the plan.
+// It returns false if the user aborts.
+func (r *Runner) selectEvents(ctx context.Context) (bool, error) {
+	selection := r.newEventSelection()
+	for {
+		r.printSelection(selection)
+		fmt.Fprint(r.Output, "Toggle events by number (e.g. \"3\" or \"1-4 7\") or series by letter, \"all\", \"none\", \"y\" to confirm the selection, \"q\" to abort: ")
+		line, err := r.readLine(ctx, "read selection")
+		if err != nil {
+			return false, err
+		}
+		switch command := strings.ToLower(strings.TrimSpace(line)); command {
+		case "y":
+			r.applySelection(selection)
+			return true, nil
+		case "q":
+			fmt.Fprint(r.Output, "Aborting...\r\n")
+			return false, nil
+		case "all":
+			clear(selection.excluded)
+		case "none":
+			for i := range selection.entries {
+				selection.excluded[i] = true
+			}
+		case "":
+		default:
+			if err := selection.toggle(line); err != nil {
+				fmt.Fprintf(r.Output, "%v\r\n", err)
+			}
+		}
+	}
+}
+
+func (r *Runner) printSelection(selection *eventSelection) {
+	for _, series := range selection.series {
+		data := r.Plan.Series[series.key]
+		selected := 0
+		for _, index := range series.entries {
+			if !selection.excluded[index] {
+				selected++
+			}
+		}
+		fmt.Fprintf(r.Output, "%v) \"%v\": file \"%v\", %v of %v events selected\r\n", series.label, series.key, data.FileToUpload, selected, len(series.entries))
+		for _, index := range series.entries {
+			event := selection.entries[index].event
+			mark := "x"
+			if selection.excluded[index] {
+				mark = " "
+			}
+			note := ""
+			if scheduled, mismatch := r.durationMismatch(data, event); mismatch {
+				note = fmt.Sprintf(" (file plays %v, event is scheduled for %v)", data.AudioDuration, scheduled)
+			}
+			fmt.Fprintf(r.Output, "  [%v] %3d  %v  Event %d  %v%v\r\n", mark, index+1, r.formatEventStart(event), event.EventID, event.Title, note)
+		}
+	}
+}
+
+// toggle flips the events and series named in input. Nothing changes if any part is invalid.
+func (s *eventSelection) toggle(input string) error {
+	var events []int
+	var series []selectionSeries
+	for _, token := range strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if found, ok := s.seriesByLabel(token); ok {
+			series = append(series, found)
+			continue
+		}
+		indexes, err := s.parseEventNumbers(token)
+		if err != nil {
+			return err
+		}
+		events = append(events, indexes...)
+	}
+	for _, index := range events {
+		s.excluded[index] = !s.excluded[index]
+	}
+	for _, found := range series {
+		s.toggleSeries(found)
+	}
+	return nil
+}
+
+func (s *eventSelection) seriesByLabel(token string) (selectionSeries, bool) {
+	for _, series := range s.series {
+		if strings.EqualFold(series.label, token) {
+			return series, true
+		}
+	}
+	return selectionSeries{}, false
+}
+
+// parseEventNumbers parses "3" or "1-4" into entry indexes.
+func (s *eventSelection) parseEventNumbers(token string) ([]int, error) {
+	first, last, ok := parseNumberRange(token)
+	if !ok {
+		return nil, fmt.Errorf("%q is neither an event number nor a series letter", token)
+	}
+	if first < 1 || last > len(s.entries) || first > last {
+		return nil, fmt.Errorf("%q is outside the event numbers 1 .. %v", token, len(s.entries))
+	}
+	return numberIndexes(first, last), nil
+}
+
+// parseNumberRange parses "3" or "1-4" into the first and last number.
+func parseNumberRange(token string) (int, int, bool) {
+	firstText, lastText, isRange := strings.Cut(token, "-")
+	if !isRange {
+		lastText = firstText
+	}
+	first, errFirst := strconv.Atoi(firstText)
+	last, errLast := strconv.Atoi(lastText)
+	return first, last, errFirst == nil && errLast == nil
+}
+
+// numberIndexes returns the zero-based indexes of the numbers first..last.
+func numberIndexes(first, last int) []int {
+	indexes := make([]int, 0, last-first+1)
+	for number := first; number <= last; number++ {
+		indexes = append(indexes, number-1)
+	}
+	return indexes
+}
+
+// toggleSeries selects all events of a series if all are left out, and leaves all out otherwise.
+func (s *eventSelection) toggleSeries(series selectionSeries) {
+	allExcluded := true
+	for _, index := range series.entries {
+		allExcluded = allExcluded && s.excluded[index]
+	}
+	for _, index := range series.entries {
+		s.excluded[index] = !allExcluded
+	}
+}
+
+// applySelection removes the events left out from the plan.
+func (r *Runner) applySelection(selection *eventSelection) {
+	var leftOut []int
+	for _, series := range selection.series {
+		data := r.Plan.Series[series.key]
+		data.Events = nil
+		for _, index := range series.entries {
+			event := selection.entries[index].event
+			if selection.excluded[index] {
+				leftOut = append(leftOut, event.EventID)
+				continue
+			}
+			data.Events = append(data.Events, event)
+		}
+		r.Plan.Series[series.key] = data
+	}
+	if len(leftOut) > 0 {
+		fmt.Fprintf(r.Output, "Leaving out events with IDs %v\r\n", leftOut)
+	}
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func selectionTestRunner(input string) (*Runner, *bytes.Buffer) {
+	runner := testRunner(&recordingTestService{})
+	output := &bytes.Buffer{}
+	runner.Output = output
+	runner.Input = bufio.NewScanner(strings.NewReader(input))
+	runner.Plan.Series = map[string]domain.SeriesPlan{
+		"morning": {SeriesInfo: domain.SeriesInfo{SeriesID: 1, FileToUpload: "morning.stream"}, Events: []domain.CalCMSEvent{
+			{EventID: 10, Skey: "morning", Title: "Morning Show", StartDateTime: "2026-07-22T06:00:00"},
+			{EventID: 11, Skey: "morning", Title: "Morning Show Special", StartDateTime: "2026-07-23T06:00:00"},
+			{EventID: 12, Skey: "morning", Title: "Morning Show", StartDateTime: "2026-07-24T06:00:00"},
+		}},
+		"night": {SeriesInfo: domain.SeriesInfo{SeriesID: 2, FileToUpload: "night.stream"}, Events: []domain.CalCMSEvent{
+			{EventID: 20, Skey: "night", Title: "Night Mix", StartDateTime: "2026-07-22T23:00:00"},
+		}},
+	}
+	return runner, output
+}
+
+func selectedIDs(runner *Runner) string {
+	return fmt.Sprint(runner.Plan.Series["morning"].EventIDs(), runner.Plan.Series["night"].EventIDs())
+}
+
+func TestSelectEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "confirm everything", input: "y\n", want: "[10 11 12] [20]"},
+		{name: "leave out one event", input: "2\ny\n", want: "[10 12] [20]"},
+		{name: "toggle twice", input: "2\n2\ny\n", want: "[10 11 12] [20]"},
+		{name: "leave out a series", input: "b\ny\n", want: "[10 11 12] []"},
+		{name: "range and series in one line", input: "1-2, B\ny\n", want: "[12] []"},
+		{name: "none then one series", input: "none\na\ny\n", want: "[10 11 12] []"},
+		{name: "invalid input changes nothing", input: "1 9\nx\ny\n", want: "[10 11 12] [20]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, _ := selectionTestRunner(tt.input)
+			confirmed, err := runner.selectEvents(t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !confirmed {
+				t.Fatal("selection was not confirmed")
+			}
+			if got := selectedIDs(runner); got != tt.want {
+				t.Fatalf("selected events = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectEventsAbortKeepsPlan(t *testing.T) {
+	runner, output := selectionTestRunner("2\nq\n")
+	confirmed, err := runner.selectEvents(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmed || selectedIDs(runner) != "[10 11 12] [20]" {
+		t.Fatalf("confirmed = %v, events = %s", confirmed, selectedIDs(runner))
+	}
+	for _, want := range []string{
+		"A) \"morning\": file \"morning.stream\", 3 of 3 events selected",
+		"  [x]   2  2026-07-23 06:00 UTC  Event 11  Morning Show Special",
+		"  [ ]   2  2026-07-23 06:00 UTC  Event 11  Morning Show Special",
+		"B) \"night\": file \"night.stream\", 1 of 1 events selected",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("output misses %q:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestConfirmationOffersSelection(t *testing.T) {
+	runner, output := selectionTestRunner("s\n3\ny\n")
+	confirmed, err := runner.showStatusAndConfirm(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !confirmed || selectedIDs(runner) != "[10 11] [20]" {
+		t.Fatalf("confirmed = %v, events = %s", confirmed, selectedIDs(runner))
+	}
+	if !strings.Contains(output.String(), "Leaving out events with IDs [12]") {
+		t.Fatalf("output misses the left out events:\n%s", output.String())
+	}
+}
+
+func TestSeriesLabel(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 15: "P", 16: "R", 22: "X", 23: "Z", 24: "AA", 25: "AB", 599: "ZZ", 600: "AAA"} {
+		if got := seriesLabel(index); got != want {
+			t.Fatalf("seriesLabel(%d) = %s, want %s", index, got, want)
+		}
+	}
+}
+
+func TestSelectEventsWithManySeries(t *testing.T) {
+	runner, output := selectionTestRunner("")
+	runner.Plan.Series = map[string]domain.SeriesPlan{}
+	for i := range 26 {
+		key := fmt.Sprintf("series-%02d", i)
+		runner.Plan.Series[key] = domain.SeriesPlan{Events: []domain.CalCMSEvent{{EventID: 100 + i, Skey: key}}}
+	}
+	runner.Input = bufio.NewScanner(strings.NewReader("Z AA\ny\n"))
+	confirmed, err := runner.selectEvents(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !confirmed {
+		t.Fatal("selection was not confirmed")
+	}
+	for _, key := range []string{"series-23", "series-24"} {
+		if events := runner.Plan.Series[key].Events; len(events) != 0 {
+			t.Fatalf("events of %s = %v, want the series left out", key, events)
+		}
+	}
+	if events := runner.Plan.Series["series-16"].Events; len(events) != 1 {
+		t.Fatalf("events of series-16 = %v, want it kept", events)
+	}
+	for _, label := range []string{"Q)", "Y)"} {
+		if strings.Contains(output.String(), "\r\n"+label) {
+			t.Fatalf("output uses command letter %s as series label:\n%s", label, output.String())
+		}
+	}
+}
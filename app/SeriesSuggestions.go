@@ -0,0 +1,146 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxSuggestions limits how many similar series keys are suggested for one key.
+const maxSuggestions = 3
+
+// normalizeSeriesKey drops case, whitespace and punctuation, so that "Radio Zett!"
+// and "radio zett" compare equal.
+func normalizeSeriesKey(key string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(key) {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// levenshtein returns the number of single-rune edits that turn a into b.
+func levenshtein(a, b string) int {
+	left, right := []rune(a), []rune(b)
+	previous := make([]int, len(right)+1)
+	current := make([]int, len(right)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(left); i++ {
+		current[0] = i
+		for j := 1; j <= len(right); j++ {
+			cost := 1
+			if left[i-1] == right[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(right)]
+}
+
+// seriesKeyDistance compares two keys after normalization. ok is false if the keys
+// are too different to be a typo or a rename of each other.
+func seriesKeyDistance(a, b string) (distance int, ok bool) {
+	left, right := normalizeSeriesKey(a), normalizeSeriesKey(b)
+	if left == "" || right == "" {
+		return 0, false
+	}
+	distance = levenshtein(left, right)
+	allowed := max(1, min(3, min(len([]rune(left)), len([]rune(right)))/4))
+	return distance, distance <= allowed
+}
+
+// similarSeriesKeys returns the candidates close to key, closest first.
+func similarSeriesKeys(key string, candidates []string) []string {
+	type match struct {
+		key      string
+		distance int
+	}
+	var matches []match
+	for _, candidate := range candidates {
+		if candidate == key {
+			continue
+		}
+		if distance, ok := seriesKeyDistance(key, candidate); ok {
+			matches = append(matches, match{key: candidate, distance: distance})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].key < matches[j].key
+	})
+	keys := make([]string, 0, min(len(matches), maxSuggestions))
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		keys = append(keys, matches[i].key)
+	}
+	return keys
+}
+
+// didYouMean formats suggestions as: did you mean "A" or "B"?
+func didYouMean(suggestions []string) string {
+	quoted := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", suggestion)
+	}
+	return fmt.Sprintf("did you mean %v?", strings.Join(quoted, " or "))
+}
+
+// noteOtherSeriesKey remembers the key of an event that belongs to no configured series.
+// It is safe for concurrent use while events are queried.
+func (r *Runner) noteOtherSeriesKey(key string) {
+	r.otherKeysMu.Lock()
+	defer r.otherKeysMu.Unlock()
+	if r.otherSeriesKeys == nil {
+		r.otherSeriesKeys = make(map[string]bool)
+	}
+	r.otherSeriesKeys[key] = true
+}
+
+func (r *Runner) forgetOtherSeriesKeys() {
+	r.otherKeysMu.Lock()
+	defer r.otherKeysMu.Unlock()
+	r.otherSeriesKeys = nil
+}
+
+// sortedOtherSeriesKeys returns the keys noted by noteOtherSeriesKey.
+func (r *Runner) sortedOtherSeriesKeys() []string {
+	r.otherKeysMu.Lock()
+	defer r.otherKeysMu.Unlock()
+	keys := make([]string, 0, len(r.otherSeriesKeys))
+	for key := range r.otherSeriesKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// warnSeriesKeyMismatches points out configured series without events whose key looks like
+// one calCMS returned, and returned keys that look like a configured series. Both usually
+// mean a typo in SERIES_FILES or a series renamed in calCMS.
+func (r *Runner) warnSeriesKeyMismatches() {
+	others := r.sortedOtherSeriesKeys()
+	if len(others) == 0 {
+		return
+	}
+	for _, key := range r.sortedSeriesKeys() {
+		suggestions := similarSeriesKeys(key, others)
+		if len(suggestions) == 0 {
+			continue
+		}
+		if len(r.Plan.Series[key].Events) == 0 {
+			fmt.Fprintf(r.Output, "Warning: no events found for series \"%v\", %v\r\n", key, didYouMean(suggestions))
+			continue
+		}
+		for _, suggestion := range suggestions {
+			fmt.Fprintf(r.Output, "Warning: calCMS also returned events of \"%v\", which is not configured but looks like \"%v\".\r\n", suggestion, key)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"zett", "", 4},
+		{"kitten", "sitting", 3},
+		{"morgenmagazin", "morgenmagazin", 0},
+		{"über", "uber", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarSeriesKeys(t *testing.T) {
+	candidates := []string{"Radio Zett!", "radio  zett", "Morgenmagazin", "Morgen Magazin Spezial", "Abendmagazin", "Zett"}
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{key: "Radio Zett", want: []string{"Radio Zett!", "radio  zett"}},
+		{key: "Morgenmagazn", want: []string{"Morgenmagazin"}},
+		{key: "Magazin von Radio F.R.E.I.", want: []string{}},
+		{key: "Zet", want: []string{"Zett"}},
+	}
+	for _, tt := range tests {
+		if got := similarSeriesKeys(tt.key, candidates); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("similarSeriesKeys(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestQueryWarnsAboutSimilarSeriesKeys(t *testing.T) {
+	fake := &recordingTestService{events: []domain.CalCMSEvent{
+		{EventID: 1, Skey: "Radio Zett!"},
+		{EventID: 2, Skey: "Show "},
+		{EventID: 3, Skey: "show"},
+		{EventID: 4, Skey: "Something else"},
+	}}
+	runner := testRunner(fake)
+	runner.Service = filteringTestSource{fake, runner.IsConfiguredSeries}
+	runner.Plan.Series["Radio Zett"] = domain.SeriesPlan{SeriesInfo: domain.SeriesInfo{SeriesID: 396}}
+	output := &bytes.Buffer{}
+	runner.Output = output
+	runner.setDayRange(runner.today(), runner.today())
+	if err := runner.queryCalCMSEvents(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	want := "Warning: no events found for series \"Radio Zett\", did you mean \"Radio Zett!\"?\r\n" +
+		"Warning: calCMS also returned events of \"Show \", which is not configured but looks like \"show\".\r\n"
+	if output.String() != want {
+		t.Fatalf("output =\n%q\nwant\n%q", output, want)
+	}
+}
+
+// filteringTestSource drops events while querying, like the calCMS service with KeepEvent set.
+type filteringTestSource struct {
+	*recordingTestService
+	keep func(domain.CalCMSEvent) bool
+}
+
+func (s filteringTestSource) QueryEvents(ctx context.Context, from, till time.Time) ([]domain.CalCMSEvent, error) {
+	events, err := s.recordingTestService.QueryEvents(ctx, from, till)
+	var kept []domain.CalCMSEvent
+	for _, event := range events {
+		if s.keep(event) {
+			kept = append(kept, event)
+		}
+	}
+	return kept, err
+}
+
+func TestCheckSuggestsSimilarSeriesKeys(t *testing.T) {
+	fake := &recordingTestService{
+		seriesNames: map[int]string{99: "Show"},
+		events:      []domain.CalCMSEvent{{EventID: 1, Skey: "Show!", StartDateTime: "2026-07-28T08:00:00"}},
+	}
+	runner := testRunner(fake)
+	output := &bytes.Buffer{}
+	runner.Output = output
+	if err := runner.Check(t.Context(), 28, 28); err == nil {
+		t.Fatal("Check() passed for a series without events")
+	}
+	if !strings.Contains(output.String(), `has this series key, did you mean "Show!"?`) {
+		t.Fatalf("output = %s", output)
+	}
+}
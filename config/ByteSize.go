@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes that accepts units such as "512KiB", "20MB" or "1GiB".
+type ByteSize int64
+
+var byteSizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+	{"KB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// Decode parses a byte size from the environment.
+func (b *ByteSize) Decode(value string) error {
+	size, err := ParseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// ParseByteSize parses a non-negative size with an optional binary or decimal unit.
+func ParseByteSize(value string) (ByteSize, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	factor := int64(1)
+	for _, unit := range byteSizeUnits {
+		if trimmed, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, factor = strings.TrimSpace(trimmed), unit.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid byte size %q", value)
+	}
+	size := n * float64(factor)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size %q is too large", value)
+	}
+	return ByteSize(size), nil
+}
+
+// String formats the size with the largest fitting binary unit.
+func (b ByteSize) String() string {
+	switch {
+	case b >= 1<<30:
+		return fmt.Sprintf("%.1fGiB", float64(b)/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(b)/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(b)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", int64(b))
+}
+
+// ByteRate is a transfer rate in bytes per second such as "2MiB/s". Zero means unlimited.
+type ByteRate int64
+
+// Decode parses a transfer rate from the environment.
+func (r *ByteRate) Decode(value string) error {
+	size, err := ParseByteSize(strings.TrimSuffix(strings.TrimSpace(value), "/s"))
+	if err != nil {
+		return fmt.Errorf("invalid transfer rate %q", value)
+	}
+	*r = ByteRate(size)
+	return nil
+}
+
+// String formats the rate with the largest fitting binary unit.
+func (r ByteRate) String() string {
+	if r == 0 {
+		return "unlimited"
+	}
+	return ByteSize(r).String() + "/s"
+}
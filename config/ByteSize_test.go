@@ -0,0 +1,33 @@
+package config
+
+import "testing"
+
+func TestParseByteSizeAndRate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{value: "1", want: 1},
+		{value: "512KiB", want: 512 << 10},
+		{value: "20MB", want: 20_000_000},
+		{value: "1.5 GiB", want: 3 << 29},
+		{value: "2mib/s", want: 2 << 20},
+		{value: "4GiB", want: 4 << 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var rate ByteRate
+			if err := rate.Decode(tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if int64(rate) != tt.want {
+				t.Fatalf("Decode(%q) = %d, want %d", tt.value, rate, tt.want)
+			}
+		})
+	}
+	for _, invalid := range []string{"", "fast", "-1MiB", "2MiB/h", "NaN", "Inf", "+Inf GiB", "1e30GiB", "8EiB", "9223372036854775808"} {
+		if _, err := ParseByteSize(invalid); err == nil {
+			t.Fatalf("ParseByteSize(%q) succeeded", invalid)
+		}
+	}
+}
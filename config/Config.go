@@ -2,18 +2,52 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+	// CALCMS_TIMEZONE must not depend on the zone database of the host.
+	_ "time/tzdata"
 
 	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+	"github.com/johannes-kuhfuss/calcmsfeeder/media"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 )
 
+// Policies for audio files whose duration does not match the scheduled event length
+const (
+	DurationMismatchWarn   = "warn"
+	DurationMismatchRefuse = "refuse"
+)
+
+// Supported sources for the events to process
+const (
+	EventSourceCalCms    = "calcms"
+	EventSourceJSON      = "json"
+	EventSourceICalendar = "ical"
+)
+
+// ErrInvalidConfig marks all errors of the Init functions, so callers can tell
+// configuration problems apart from failures at run time.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+func invalidConfig(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+}
+
+// maxQueryParallelism bounds the concurrent event queries so the feeder stays polite to calCMS.
+const maxQueryParallelism = 8
+
 // Configuration with subsections
 type AppConfig struct {
 	CalCms struct {
@@ -26,21 +60,134 @@ type AppConfig struct {
 		DefaultDurationInDays int               `envconfig:"DEFAULT_DURATION_IN_DAYS" default:"7"`
 		MaxDurationInDays     int               `envconfig:"MAX_DURATION_IN_DAYS" default:"60"`
 		RequestTimeout        time.Duration     `envconfig:"CALCMS_REQUEST_TIMEOUT" default:"5m"`
+		ConnectTimeout        time.Duration     `envconfig:"CALCMS_CONNECT_TIMEOUT" default:"0"`
+		QueryTimeout          time.Duration     `envconfig:"CALCMS_QUERY_TIMEOUT" default:"0"`
+		LoginTimeout          time.Duration     `envconfig:"CALCMS_LOGIN_TIMEOUT" default:"0"`
+		CheckTimeout          time.Duration     `envconfig:"CALCMS_CHECK_TIMEOUT" default:"0"`
+		UploadTimeout         time.Duration     `envconfig:"CALCMS_UPLOAD_TIMEOUT" default:"0"`
+		QueryWindowDays       int               `envconfig:"CALCMS_QUERY_WINDOW_DAYS" default:"7"`
+		QueryParallelism      int               `envconfig:"CALCMS_QUERY_PARALLELISM" default:"1"`
+		MaxResponseSize       ByteSize          `envconfig:"CALCMS_MAX_RESPONSE_SIZE" default:"4MiB"`
+		Timezone              string            `envconfig:"CALCMS_TIMEZONE" default:"Local"`
 		SeriesFiles           map[string]string `envconfig:"SERIES_FILES"`
 		SeriesIDs             map[string]int    `envconfig:"SERIES_IDS"`
 	}
-	Series map[string]domain.SeriesInfo `ignored:"true"`
+	Transport struct {
+		Proxy          string            `envconfig:"CALCMS_PROXY"`
+		CAFile         string            `envconfig:"CALCMS_CA_FILE"`
+		ClientCertFile string            `envconfig:"CALCMS_CLIENT_CERT"`
+		ClientKeyFile  string            `envconfig:"CALCMS_CLIENT_KEY"`
+		PinnedCert     string            `envconfig:"CALCMS_PINNED_CERT_SHA256"`
+		ProxyURL       *url.URL          `ignored:"true"`
+		RootCAs        *x509.CertPool    `ignored:"true"`
+		Certificates   []tls.Certificate `ignored:"true"`
+		PinnedSHA256   []byte            `ignored:"true"`
+	}
+	Events struct {
+		Source string `envconfig:"EVENT_SOURCE" default:"calcms"`
+		File   string `envconfig:"EVENT_SOURCE_FILE"`
+	}
+	Uploads struct {
+		MinFileSize       ByteSize      `envconfig:"UPLOAD_MIN_FILE_SIZE" default:"1"`
+		MaxFileSize       ByteSize      `envconfig:"UPLOAD_MAX_FILE_SIZE" default:"2GiB"`
+		DurationTolerance time.Duration `envconfig:"DURATION_TOLERANCE" default:"2m"`
+		DurationMismatch  string        `envconfig:"DURATION_MISMATCH" default:"warn"`
+		Rate              ByteRate      `envconfig:"CALCMS_UPLOAD_RATE" default:"0"`
+		MinRate           ByteRate      `envconfig:"CALCMS_UPLOAD_MIN_RATE" default:"0"`
+	}
+	Relays struct {
+		Dir         string       `envconfig:"STREAM_RELAY_DIR" default:"./uploadfiles"`
+		Streams     StreamRelays `envconfig:"STREAM_RELAYS"`
+		ResolvedDir string       `ignored:"true"`
+	}
+	Audit struct {
+		File         string `envconfig:"AUDIT_LOG" default:"./audit.jsonl"`
+		ResolvedFile string `ignored:"true"`
+	}
+	Series   map[string]domain.SeriesInfo `ignored:"true"`
+	Warnings []string                     `ignored:"true"`
+	Location *time.Location               `ignored:"true"`
+}
+
+// TimeLocation returns the time zone of calCMS dates and event times.
+// It falls back to the local time zone for configurations that were not validated.
+func (c *AppConfig) TimeLocation() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
 }
 
 // InitConfig initializes the configuration and sets the defaults
 func InitConfig(file string, config *AppConfig) error {
+	if err := processConfig(file, config); err != nil {
+		return invalidConfig(err)
+	}
+	if err := validateRelays(config, filepath.Dir(file)); err != nil {
+		return invalidConfig(err)
+	}
+	if err := validateAuditLog(config, filepath.Dir(file)); err != nil {
+		return invalidConfig(err)
+	}
+	if err := validateTransport(config, filepath.Dir(file)); err != nil {
+		return invalidConfig(err)
+	}
+	return invalidConfig(validateAndBuildSeries(config, filepath.Dir(file)))
+}
+
+// InitRelayConfig initializes the configuration needed to generate stream files.
+// Unlike InitConfig it does not require credentials or existing upload files.
+func InitRelayConfig(file string, config *AppConfig) error {
+	if err := processConfig(file, config); err != nil {
+		return invalidConfig(err)
+	}
+	return invalidConfig(validateRelays(config, filepath.Dir(file)))
+}
+
+// InitAuditConfig initializes the configuration needed to read the audit log.
+func InitAuditConfig(file string, config *AppConfig) error {
+	if err := processConfig(file, config); err != nil {
+		return invalidConfig(err)
+	}
+	return invalidConfig(validateAuditLog(config, filepath.Dir(file)))
+}
+
+// validateAuditLog resolves AUDIT_LOG relative to the configuration file.
+func validateAuditLog(config *AppConfig, baseDir string) error {
+	file := config.Audit.File
+	if strings.TrimSpace(file) == "" {
+		return fmt.Errorf("AUDIT_LOG must not be empty")
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(baseDir, file)
+	}
+	config.Audit.ResolvedFile = filepath.Clean(file)
+	return nil
+}
+
+// InitCalCmsConfig initializes the configuration needed to change recordings in calCMS
+// outside of an upload run. Unlike InitConfig it does not require the upload files.
+func InitCalCmsConfig(file string, config *AppConfig) error {
+	if err := processConfig(file, config); err != nil {
+		return invalidConfig(err)
+	}
+	if err := validateAuditLog(config, filepath.Dir(file)); err != nil {
+		return invalidConfig(err)
+	}
+	if err := validateTransport(config, filepath.Dir(file)); err != nil {
+		return invalidConfig(err)
+	}
+	return invalidConfig(validateCalCms(config))
+}
+
+func processConfig(file string, config *AppConfig) error {
 	if err := loadConfig(file); err != nil {
 		return fmt.Errorf("load configuration from file: %w", err)
 	}
 	if err := envconfig.Process("", config); err != nil {
 		return fmt.Errorf("initialize configuration: %w", err)
 	}
-	return validateAndBuildSeries(config, filepath.Dir(file))
+	return nil
 }
 
 // checkFilePath validates and resolves an upload file path.
@@ -67,6 +214,65 @@ func checkFilePath(filePath, baseDir string) (string, error) {
 }
 
 func validateAndBuildSeries(config *AppConfig, baseDir string) error {
+	if err := validateCalCms(config); err != nil {
+		return err
+	}
+	if err := validateEventSource(config, baseDir); err != nil {
+		return err
+	}
+	if len(config.CalCms.SeriesFiles) == 0 {
+		return fmt.Errorf("SERIES_FILES must contain at least one entry")
+	}
+	if config.Uploads.MaxFileSize > 0 && config.Uploads.MinFileSize > config.Uploads.MaxFileSize {
+		return fmt.Errorf("UPLOAD_MIN_FILE_SIZE must not exceed UPLOAD_MAX_FILE_SIZE")
+	}
+	if config.Uploads.DurationTolerance < 0 {
+		return fmt.Errorf("DURATION_TOLERANCE must not be negative")
+	}
+	if config.Uploads.DurationMismatch != DurationMismatchWarn && config.Uploads.DurationMismatch != DurationMismatchRefuse {
+		return fmt.Errorf("DURATION_MISMATCH must be %q or %q", DurationMismatchWarn, DurationMismatchRefuse)
+	}
+	config.Series = make(map[string]domain.SeriesInfo)
+	var fileErrs []error
+	for _, skey := range sortedKeys(config.CalCms.SeriesFiles) {
+		seriesID, ok := config.CalCms.SeriesIDs[skey]
+		if !ok || seriesID < 1 {
+			return fmt.Errorf("SERIES_IDS must contain a positive ID for %q", skey)
+		}
+		var format media.Format
+		var duration time.Duration
+		file, err := checkFilePath(config.CalCms.SeriesFiles[skey], baseDir)
+		if err == nil {
+			format, err = validateUploadFile(file, config.Uploads.MinFileSize, config.Uploads.MaxFileSize)
+		}
+		if err != nil {
+			fileErrs = append(fileErrs, fmt.Errorf("invalid upload file for %q: %w", skey, err))
+			continue
+		}
+		if format != media.FormatStream {
+			if duration, err = media.FileDuration(file, format); err != nil {
+				duration = 0
+				config.Warnings = append(config.Warnings, fmt.Sprintf("cannot determine the duration of upload file %q for %q, skipping the duration check: %v", file, skey, err))
+			}
+		}
+		if warning := staleRelayWarning(config, skey, file); warning != "" {
+			config.Warnings = append(config.Warnings, warning)
+		}
+		config.Series[skey] = domain.SeriesInfo{FileToUpload: file, SeriesID: seriesID, AudioDuration: duration}
+	}
+	if len(fileErrs) > 0 {
+		return errors.Join(fileErrs...)
+	}
+	for skey := range config.CalCms.SeriesIDs {
+		if _, ok := config.CalCms.SeriesFiles[skey]; !ok {
+			return fmt.Errorf("SERIES_IDS contains %q without a matching file", skey)
+		}
+	}
+	return nil
+}
+
+// validateCalCms checks the settings needed to talk to calCMS.
+func validateCalCms(config *AppConfig) error {
 	if config == nil {
 		return fmt.Errorf("configuration is nil")
 	}
@@ -92,27 +298,64 @@ func validateAndBuildSeries(config *AppConfig, baseDir string) error {
 	if config.CalCms.RequestTimeout <= 0 {
 		return fmt.Errorf("CALCMS_REQUEST_TIMEOUT must be positive")
 	}
-	if len(config.CalCms.SeriesFiles) == 0 {
-		return fmt.Errorf("SERIES_FILES must contain at least one entry")
+	operationTimeouts := map[string]time.Duration{
+		"CALCMS_CONNECT_TIMEOUT": config.CalCms.ConnectTimeout,
+		"CALCMS_QUERY_TIMEOUT":   config.CalCms.QueryTimeout,
+		"CALCMS_LOGIN_TIMEOUT":   config.CalCms.LoginTimeout,
+		"CALCMS_CHECK_TIMEOUT":   config.CalCms.CheckTimeout,
+		"CALCMS_UPLOAD_TIMEOUT":  config.CalCms.UploadTimeout,
 	}
-	config.Series = make(map[string]domain.SeriesInfo)
-	for skey, file := range config.CalCms.SeriesFiles {
-		seriesID, ok := config.CalCms.SeriesIDs[skey]
-		if !ok || seriesID < 1 {
-			return fmt.Errorf("SERIES_IDS must contain a positive ID for %q", skey)
+	for _, name := range sortedKeys(operationTimeouts) {
+		if operationTimeouts[name] < 0 {
+			return fmt.Errorf("%v must not be negative", name)
 		}
-		file, err = checkFilePath(file, baseDir)
+	}
+	if config.Uploads.MinRate < 0 {
+		return fmt.Errorf("CALCMS_UPLOAD_MIN_RATE must not be negative")
+	}
+	// time.LoadLocation reads "" as UTC; an emptied setting must not silently replace Local.
+	if strings.TrimSpace(config.CalCms.Timezone) == "" {
+		return fmt.Errorf("CALCMS_TIMEZONE must not be empty; remove it to use Local, or set UTC explicitly")
+	}
+	location, err := time.LoadLocation(config.CalCms.Timezone)
+	if err != nil {
+		return fmt.Errorf("CALCMS_TIMEZONE must be an IANA time zone such as Europe/Berlin: %w", err)
+	}
+	config.Location = location
+	if config.CalCms.MaxResponseSize < 1 {
+		return fmt.Errorf("CALCMS_MAX_RESPONSE_SIZE must be positive")
+	}
+	if config.CalCms.QueryWindowDays < 1 {
+		return fmt.Errorf("CALCMS_QUERY_WINDOW_DAYS must be positive")
+	}
+	if config.CalCms.QueryParallelism < 1 || config.CalCms.QueryParallelism > maxQueryParallelism {
+		return fmt.Errorf("CALCMS_QUERY_PARALLELISM must be between 1 and %d", maxQueryParallelism)
+	}
+	return nil
+}
+
+func validateEventSource(config *AppConfig, baseDir string) error {
+	switch config.Events.Source {
+	case EventSourceCalCms:
+		return nil
+	case EventSourceJSON, EventSourceICalendar:
+		file, err := checkFilePath(config.Events.File, baseDir)
 		if err != nil {
-			return fmt.Errorf("invalid upload file for %q: %w", skey, err)
+			return fmt.Errorf("invalid EVENT_SOURCE_FILE: %w", err)
 		}
-		config.Series[skey] = domain.SeriesInfo{FileToUpload: file, SeriesID: seriesID}
+		config.Events.File = file
+		return nil
 	}
-	for skey := range config.CalCms.SeriesIDs {
-		if _, ok := config.CalCms.SeriesFiles[skey]; !ok {
-			return fmt.Errorf("SERIES_IDS contains %q without a matching file", skey)
-		}
+	return fmt.Errorf("EVENT_SOURCE must be %q, %q or %q", EventSourceCalCms, EventSourceJSON, EventSourceICalendar)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
 	}
-	return nil
+	sort.Strings(keys)
+	return keys
 }
 
 // loadConfig loads the configuration from file. Returns an error if loading fails
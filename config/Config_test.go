@@ -8,6 +8,8 @@ import (
 	"time"
 )
 
+const testStreamContent = "# Stream ID for mAirlist: ++show++\nhttps://relay.example/show.mp3\n"
+
 func validTestConfig() AppConfig {
 	var cfg AppConfig
 	cfg.CalCms.CmsHost = "https://calendar.example"
@@ -19,21 +21,33 @@ func validTestConfig() AppConfig {
 	cfg.CalCms.DefaultDurationInDays = 7
 	cfg.CalCms.MaxDurationInDays = 30
 	cfg.CalCms.RequestTimeout = 5 * time.Minute
+	cfg.CalCms.QueryWindowDays = 7
+	cfg.CalCms.QueryParallelism = 1
+	cfg.CalCms.MaxResponseSize = 4 << 20
+	cfg.CalCms.Timezone = "Europe/Berlin"
+	cfg.Events.Source = EventSourceCalCms
 	cfg.CalCms.SeriesFiles = map[string]string{"show": "show.stream"}
 	cfg.CalCms.SeriesIDs = map[string]int{"show": 42}
+	cfg.Uploads.MinFileSize = 1
+	cfg.Uploads.MaxFileSize = 1 << 30
+	cfg.Uploads.DurationTolerance = 2 * time.Minute
+	cfg.Uploads.DurationMismatch = DurationMismatchWarn
 	return cfg
 }
 
 func TestValidateAndBuildRuntimeResolvesRelativeFile(t *testing.T) {
 	dir := t.TempDir()
 	file := filepath.Join(dir, "show.stream")
-	if err := os.WriteFile(file, []byte("stream contents"), 0o600); err != nil {
+	if err := os.WriteFile(file, []byte(testStreamContent), 0o600); err != nil {
 		t.Fatal(err)
 	}
 	cfg := validTestConfig()
 	if err := validateAndBuildSeries(&cfg, dir); err != nil {
 		t.Fatalf("validateAndBuildSeries() error = %v", err)
 	}
+	if cfg.TimeLocation().String() != "Europe/Berlin" {
+		t.Fatalf("time location = %v, want Europe/Berlin", cfg.TimeLocation())
+	}
 	got := cfg.Series["show"]
 	if got.SeriesID != 42 {
 		t.Fatalf("series ID = %d, want 42", got.SeriesID)
@@ -49,7 +63,7 @@ func TestValidateAndBuildRuntimeResolvesRelativeFile(t *testing.T) {
 
 func TestValidateAndBuildRuntimeRejectsUnsafeOrIncompleteConfig(t *testing.T) {
 	dir := t.TempDir()
-	if err := os.WriteFile(filepath.Join(dir, "show.stream"), []byte("data"), 0o600); err != nil {
+	if err := os.WriteFile(filepath.Join(dir, "show.stream"), []byte(testStreamContent), 0o600); err != nil {
 		t.Fatal(err)
 	}
 	tests := []struct {
@@ -61,6 +75,14 @@ func TestValidateAndBuildRuntimeRejectsUnsafeOrIncompleteConfig(t *testing.T) {
 		{name: "missing credentials", mutate: func(c *AppConfig) { c.CalCms.CmsPass = "" }, want: "are required"},
 		{name: "invalid duration", mutate: func(c *AppConfig) { c.CalCms.DefaultDurationInDays = 31 }, want: "1 <= default <= maximum"},
 		{name: "invalid request timeout", mutate: func(c *AppConfig) { c.CalCms.RequestTimeout = 0 }, want: "must be positive"},
+		{name: "negative upload timeout", mutate: func(c *AppConfig) { c.CalCms.UploadTimeout = -time.Second }, want: "CALCMS_UPLOAD_TIMEOUT must not be negative"},
+		{name: "invalid duration policy", mutate: func(c *AppConfig) { c.Uploads.DurationMismatch = "ignore" }, want: "DURATION_MISMATCH"},
+		{name: "event file missing", mutate: func(c *AppConfig) { c.Events.Source = EventSourceJSON; c.Events.File = "events.json" }, want: "invalid EVENT_SOURCE_FILE"},
+		{name: "unknown event source", mutate: func(c *AppConfig) { c.Events.Source = "ftp" }, want: "EVENT_SOURCE must be"},
+		{name: "empty time zone", mutate: func(c *AppConfig) { c.CalCms.Timezone = "" }, want: "CALCMS_TIMEZONE must not be empty"},
+		{name: "unknown time zone", mutate: func(c *AppConfig) { c.CalCms.Timezone = "Europe/Atlantis" }, want: "CALCMS_TIMEZONE"},
+		{name: "invalid query window", mutate: func(c *AppConfig) { c.CalCms.QueryWindowDays = 0 }, want: "CALCMS_QUERY_WINDOW_DAYS"},
+		{name: "too many parallel queries", mutate: func(c *AppConfig) { c.CalCms.QueryParallelism = 20 }, want: "CALCMS_QUERY_PARALLELISM"},
 		{name: "missing series ID", mutate: func(c *AppConfig) { delete(c.CalCms.SeriesIDs, "show") }, want: "positive ID"},
 		{name: "missing upload file", mutate: func(c *AppConfig) { c.CalCms.SeriesFiles["show"] = "missing.stream" }, want: "invalid upload file"},
 	}
@@ -75,3 +97,18 @@ func TestValidateAndBuildRuntimeRejectsUnsafeOrIncompleteConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAuditLog(t *testing.T) {
+	cfg := AppConfig{}
+	cfg.Audit.File = "logs/audit.jsonl"
+	if err := validateAuditLog(&cfg, "/etc/calcmsfeeder"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Audit.ResolvedFile != filepath.Join("/etc/calcmsfeeder", "logs", "audit.jsonl") {
+		t.Fatalf("resolved audit log = %q", cfg.Audit.ResolvedFile)
+	}
+	cfg.Audit.File = " "
+	if err := validateAuditLog(&cfg, "."); err == nil {
+		t.Fatal("validateAuditLog accepted an empty AUDIT_LOG")
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/media"
+)
+
+// StreamRelays maps mAirlist stream IDs to relay URLs.
+// The environment format is "id=url,id=url", because URLs contain colons.
+type StreamRelays map[string]string
+
+// Decode parses the STREAM_RELAYS environment value.
+func (r *StreamRelays) Decode(value string) error {
+	relays := make(StreamRelays)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		id, relayURL, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid relay %q, want stream-id=url", pair)
+		}
+		id, relayURL = strings.TrimSpace(id), strings.TrimSpace(relayURL)
+		if _, exists := relays[id]; exists {
+			return fmt.Errorf("duplicate relay %q", id)
+		}
+		relays[id] = relayURL
+	}
+	*r = relays
+	return nil
+}
+
+// StreamFiles returns the configured relays sorted by stream ID.
+func (r StreamRelays) StreamFiles() []media.StreamFile {
+	files := make([]media.StreamFile, 0, len(r))
+	for id, relayURL := range r {
+		files = append(files, media.StreamFile{StreamID: id, URL: relayURL})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].StreamID < files[j].StreamID })
+	return files
+}
+
+// RelayFilePath returns the path of the generated file for a stream.
+func (c *AppConfig) RelayFilePath(stream media.StreamFile) string {
+	return filepath.Join(c.Relays.ResolvedDir, stream.FileName())
+}
+
+func validateRelays(config *AppConfig, baseDir string) error {
+	if config == nil {
+		return fmt.Errorf("configuration is nil")
+	}
+	dir := config.Relays.Dir
+	if strings.TrimSpace(dir) == "" {
+		return fmt.Errorf("STREAM_RELAY_DIR must not be empty")
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+	config.Relays.ResolvedDir = filepath.Clean(dir)
+	for _, stream := range config.Relays.Streams.StreamFiles() {
+		if err := stream.Validate(); err != nil {
+			return fmt.Errorf("invalid STREAM_RELAYS entry %q: %w", stream.StreamID, err)
+		}
+	}
+	return nil
+}
+
+// staleRelayWarning reports a series file that is generated from STREAM_RELAYS but no longer matches it.
+func staleRelayWarning(config *AppConfig, skey, file string) string {
+	for _, stream := range config.Relays.Streams.StreamFiles() {
+		generated := config.RelayFilePath(stream)
+		if resolved, err := filepath.EvalSymlinks(generated); err == nil {
+			generated = resolved
+		}
+		if generated != file {
+			continue
+		}
+		current, err := os.ReadFile(file)
+		if err != nil || !bytes.Equal(current, stream.Render()) {
+			return fmt.Sprintf("upload file %q for %q is out of date with STREAM_RELAYS entry %q; run the generate-streams command", file, skey, stream.StreamID)
+		}
+		return ""
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/media"
+)
+
+func TestStreamRelaysDecodeKeepsURLColons(t *testing.T) {
+	var relays StreamRelays
+	if err := relays.Decode("radiocorax=http://intern.radiocorax.de:8000/corax, radiozett=https://zett-stream.de:8000/zett.mp3"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"radiocorax": "http://intern.radiocorax.de:8000/corax",
+		"radiozett":  "https://zett-stream.de:8000/zett.mp3",
+	}
+	if len(relays) != len(want) {
+		t.Fatalf("relays = %v, want %v", relays, want)
+	}
+	for id, url := range want {
+		if relays[id] != url {
+			t.Fatalf("relay %s = %q, want %q", id, relays[id], url)
+		}
+	}
+	if err := relays.Decode("radiocorax"); err == nil {
+		t.Fatal("Decode() accepted an entry without URL")
+	}
+}
+
+func TestValidateRelaysRejectsInvalidEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		streams StreamRelays
+		want    string
+	}{
+		{name: "unsupported scheme", streams: StreamRelays{"radiocorax": "ftp://example.org/stream"}, want: "must use http or https"},
+		{name: "missing host", streams: StreamRelays{"radiocorax": "http:///stream"}, want: "has no host"},
+		{name: "unsafe stream ID", streams: StreamRelays{"../corax": "http://example.org/stream"}, want: "must only contain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Relays.Dir = "uploadfiles"
+			cfg.Relays.Streams = tt.streams
+			err := validateRelays(&cfg, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAndBuildSeriesWarnsAboutOutdatedGeneratedFile(t *testing.T) {
+	dir := t.TempDir()
+	stream := media.StreamFile{StreamID: "show", URL: "https://relay.example/show.mp3"}
+	if err := os.WriteFile(filepath.Join(dir, "show.stream"), media.StreamFile{StreamID: "show", URL: "https://old.example/show.mp3"}.Render(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := validTestConfig()
+	cfg.Relays.Dir = "."
+	cfg.Relays.Streams = StreamRelays{stream.StreamID: stream.URL}
+	if err := validateRelays(&cfg, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateAndBuildSeries(&cfg, dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], "out of date") {
+		t.Fatalf("warnings = %v, want one outdated file warning", cfg.Warnings)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "show.stream"), stream.Render(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Warnings = nil
+	if err := validateAndBuildSeries(&cfg, dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Warnings) != 0 {
+		t.Fatalf("warnings = %v, want none for a fresh file", cfg.Warnings)
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// validateTransport checks the proxy and TLS settings of the calCMS client and loads the
+// CA bundle, the client certificate, and the pinned fingerprint. Relative file paths are
+// resolved against baseDir.
+func validateTransport(config *AppConfig, baseDir string) error {
+	transport := &config.Transport
+	if transport.Proxy != "" {
+		proxy, err := url.Parse(transport.Proxy)
+		if err != nil || proxy.Host == "" || (proxy.Scheme != "http" && proxy.Scheme != "https" && proxy.Scheme != "socks5") {
+			return fmt.Errorf("CALCMS_PROXY must be an absolute http, https, or socks5 URL")
+		}
+		transport.ProxyURL = proxy
+	}
+	if transport.CAFile != "" {
+		file, err := checkFilePath(transport.CAFile, baseDir)
+		if err != nil {
+			return fmt.Errorf("invalid CALCMS_CA_FILE: %w", err)
+		}
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read CALCMS_CA_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CALCMS_CA_FILE %q holds no PEM certificate", file)
+		}
+		transport.RootCAs = pool
+	}
+	if (transport.ClientCertFile == "") != (transport.ClientKeyFile == "") {
+		return fmt.Errorf("CALCMS_CLIENT_CERT and CALCMS_CLIENT_KEY must be set together")
+	}
+	if transport.ClientCertFile != "" {
+		certFile, err := checkFilePath(transport.ClientCertFile, baseDir)
+		if err != nil {
+			return fmt.Errorf("invalid CALCMS_CLIENT_CERT: %w", err)
+		}
+		keyFile, err := checkFilePath(transport.ClientKeyFile, baseDir)
+		if err != nil {
+			return fmt.Errorf("invalid CALCMS_CLIENT_KEY: %w", err)
+		}
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("load client certificate: %w", err)
+		}
+		transport.Certificates = []tls.Certificate{certificate}
+	}
+	if transport.PinnedCert != "" {
+		pin, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(transport.PinnedCert), ":", ""))
+		if err != nil || len(pin) != sha256.Size {
+			return fmt.Errorf("CALCMS_PINNED_CERT_SHA256 must be a SHA-256 fingerprint of 64 hex digits")
+		}
+		transport.PinnedSHA256 = pin
+	}
+	return nil
+}
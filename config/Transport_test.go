@@ -0,0 +1,107 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate and its key as PEM files to dir.
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "calcmsfeeder"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestValidateTransportLoadsSettings(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+	cfg := validTestConfig()
+	cfg.Transport.Proxy = "socks5://proxy.example:1080"
+	cfg.Transport.CAFile = "client.crt"
+	cfg.Transport.ClientCertFile = certFile
+	cfg.Transport.ClientKeyFile = keyFile
+	cfg.Transport.PinnedCert = strings.Repeat("AB:", 31) + "AB"
+	if err := validateTransport(&cfg, dir); err != nil {
+		t.Fatalf("validateTransport() error = %v", err)
+	}
+	if cfg.Transport.ProxyURL == nil || cfg.Transport.ProxyURL.Host != "proxy.example:1080" {
+		t.Fatalf("ProxyURL = %v", cfg.Transport.ProxyURL)
+	}
+	if cfg.Transport.RootCAs == nil || len(cfg.Transport.Certificates) != 1 {
+		t.Fatalf("RootCAs = %v, Certificates = %d, want CA pool and one certificate", cfg.Transport.RootCAs, len(cfg.Transport.Certificates))
+	}
+	if len(cfg.Transport.PinnedSHA256) != 32 || cfg.Transport.PinnedSHA256[0] != 0xAB {
+		t.Fatalf("PinnedSHA256 = %X", cfg.Transport.PinnedSHA256)
+	}
+}
+
+func TestValidateTransportRejectsInvalidSettings(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		modify func(*AppConfig)
+		want   string
+	}{
+		{name: "relative proxy", modify: func(c *AppConfig) { c.Transport.Proxy = "proxy.example:3128" }, want: "CALCMS_PROXY"},
+		{name: "ftp proxy", modify: func(c *AppConfig) { c.Transport.Proxy = "ftp://proxy.example" }, want: "CALCMS_PROXY"},
+		{name: "CA without PEM", modify: func(c *AppConfig) { c.Transport.CAFile = notPEM }, want: "holds no PEM certificate"},
+		{name: "missing CA", modify: func(c *AppConfig) { c.Transport.CAFile = "missing.pem" }, want: "CALCMS_CA_FILE"},
+		{name: "certificate without key", modify: func(c *AppConfig) { c.Transport.ClientCertFile = certFile }, want: "must be set together"},
+		{name: "key without certificate", modify: func(c *AppConfig) { c.Transport.ClientKeyFile = keyFile }, want: "must be set together"},
+		{name: "key mismatch", modify: func(c *AppConfig) { c.Transport.ClientCertFile, c.Transport.ClientKeyFile = certFile, notPEM }, want: "load client certificate"},
+		{name: "short pin", modify: func(c *AppConfig) { c.Transport.PinnedCert = "ABCD" }, want: "CALCMS_PINNED_CERT_SHA256"},
+		{name: "non-hex pin", modify: func(c *AppConfig) { c.Transport.PinnedCert = strings.Repeat("ZZ", 32) }, want: "CALCMS_PINNED_CERT_SHA256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			tt.modify(&cfg)
+			err := validateTransport(&cfg, dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("validateTransport() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
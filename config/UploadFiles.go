@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/media"
+)
+
+// maxStreamFileSize limits stream pointer files, which only hold a comment and a URL.
+const maxStreamFileSize = 64 << 10
+
+// validateUploadFile checks the size and content of a resolved upload file.
+func validateUploadFile(path string, minSize, maxSize ByteSize) (media.Format, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := ByteSize(info.Size())
+	if size < minSize {
+		return "", fmt.Errorf("file has %v, minimum is %v", size, minSize)
+	}
+	if maxSize > 0 && size > maxSize {
+		return "", fmt.Errorf("file has %v, maximum is %v", size, maxSize)
+	}
+	if strings.EqualFold(filepath.Ext(path), media.StreamFileExtension) {
+		if size > maxStreamFileSize {
+			return "", fmt.Errorf("stream file has %v, maximum is %v", size, ByteSize(maxStreamFileSize))
+		}
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return "", err
+		}
+		if _, err := media.ParseStreamFile(data); err != nil {
+			return "", fmt.Errorf("invalid stream file: %w", err)
+		}
+		return media.FormatStream, nil
+	}
+	header := make([]byte, media.SniffHeaderSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	format, ok := media.SniffAudio(header[:n])
+	if !ok {
+		return "", fmt.Errorf("unrecognised audio format, want MP3, WAV, FLAC or OGG")
+	}
+	return format, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateUploadFileChecksContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{name: "stream file", file: "show.stream", content: testStreamContent},
+		{name: "mp3 file", file: "show.mp3", content: "ID3\x04\x00\x00\x00\x00\x00\x00audio"},
+		{name: "empty file", file: "show.stream", content: "", want: "minimum is 1B"},
+		{name: "stream without URL", file: "show.stream", content: "# Stream ID for mAirlist: ++show++\n", want: "missing stream URL"},
+		{name: "stream without ID comment", file: "show.stream", content: "https://relay.example/show.mp3\n", want: "Stream ID for mAirlist"},
+		{name: "unknown audio", file: "show.mp3", content: "<html>not audio</html>", want: "unrecognised audio format"},
+		{name: "too large", file: "show.wav", content: "RIFF\x00\x00\x00\x00WAVE" + strings.Repeat("x", 200), want: "maximum is 128B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := validateUploadFile(path, 1, 128)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("validateUploadFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAndBuildSeriesReportsEveryInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"empty.stream": "", "show.stream": testStreamContent, "noise.mp3": "noise"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := validTestConfig()
+	cfg.CalCms.SeriesFiles = map[string]string{"empty": "empty.stream", "show": "show.stream", "noise": "noise.mp3"}
+	cfg.CalCms.SeriesIDs = map[string]int{"empty": 1, "show": 2, "noise": 3}
+	err := validateAndBuildSeries(&cfg, dir)
+	if err == nil {
+		t.Fatal("validateAndBuildSeries() accepted invalid files")
+	}
+	for _, want := range []string{`"empty"`, `"noise"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %v, want report for %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), `"show"`) {
+		t.Fatalf("error = %v, valid series reported", err)
+	}
+}
+
+func TestValidateAndBuildSeriesWarnsAboutUnknownDuration(t *testing.T) {
+	dir := t.TempDir()
+	content := "OggS\x00\x02" + strings.Repeat("\x00", 20) + "\x01\x08FLAC-ish"
+	if err := os.WriteFile(filepath.Join(dir, "show.ogg"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := validTestConfig()
+	cfg.CalCms.SeriesFiles = map[string]string{"show": "show.ogg"}
+	if err := validateAndBuildSeries(&cfg, dir); err != nil {
+		t.Fatalf("validateAndBuildSeries() error = %v", err)
+	}
+	if cfg.Series["show"].AudioDuration != 0 {
+		t.Fatalf("AudioDuration = %v, want 0", cfg.Series["show"].AudioDuration)
+	}
+	if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], "cannot determine the duration") {
+		t.Fatalf("warnings = %v, want one duration warning", cfg.Warnings)
+	}
+}
@@ -1,12 +1,84 @@
 package domain
 
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// eventTimeLayouts are the date-time formats calCMS uses in event responses.
+var eventTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"}
+
 // CalCMSEvent is the subset of an event response used by the application.
 type CalCMSEvent struct {
-	EventID int    `json:"event_id"`
-	Skey    string `json:"skey"`
+	EventID       int      `json:"event_id"`
+	Skey          string   `json:"skey"`
+	Title         string   `json:"title"`
+	StartDateTime string   `json:"start_datetime"`
+	EndDateTime   string   `json:"end_datetime"`
+	SeriesID      LooseInt `json:"series_id"`
+	SeriesName    string   `json:"series_name"`
+}
+
+// LooseInt is an integer that calCMS may send as a JSON number or as a string.
+// Empty strings and null decode to zero.
+type LooseInt int
+
+// UnmarshalJSON accepts 395, "395", "" and null.
+func (n *LooseInt) UnmarshalJSON(data []byte) error {
+	text := string(bytes.Trim(data, `"`))
+	if text == "" || text == "null" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = LooseInt(value)
+	return nil
 }
 
 // CalCMSEventResponse is the relevant envelope returned by calCMS.
 type CalCMSEventResponse struct {
 	Events []CalCMSEvent `json:"events"`
 }
+
+// Start returns the scheduled start of the event in the given location.
+func (e CalCMSEvent) Start(loc *time.Location) (time.Time, error) {
+	return parseEventTime(e.StartDateTime, loc)
+}
+
+// End returns the scheduled end of the event in the given location.
+func (e CalCMSEvent) End(loc *time.Location) (time.Time, error) {
+	return parseEventTime(e.EndDateTime, loc)
+}
+
+// Duration returns the scheduled length of the event.
+func (e CalCMSEvent) Duration(loc *time.Location) (time.Duration, error) {
+	start, err := e.Start(loc)
+	if err != nil {
+		return 0, err
+	}
+	end, err := e.End(loc)
+	if err != nil {
+		return 0, err
+	}
+	if !end.After(start) {
+		return 0, fmt.Errorf("event %d ends before it starts", e.EventID)
+	}
+	return end.Sub(start), nil
+}
+
+func parseEventTime(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("event time is missing")
+	}
+	for _, layout := range eventTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid event time %q", value)
+}
@@ -7,17 +7,108 @@ import "time"
 type SeriesInfo struct {
 	FileToUpload string
 	SeriesID     int
+	// AudioDuration is the playing time of an audio upload file, or zero for stream files.
+	AudioDuration time.Duration
 }
 
 // SeriesPlan contains the configured series and the matching events for one run.
 type SeriesPlan struct {
 	SeriesInfo
-	EventIDs []int
+	Events []CalCMSEvent
+}
+
+// EventIDs returns the IDs of the matching events in plan order.
+func (p SeriesPlan) EventIDs() []int {
+	ids := make([]int, 0, len(p.Events))
+	for _, event := range p.Events {
+		ids = append(ids, event.EventID)
+	}
+	return ids
 }
 
 // ExecutionPlan contains all mutable state for one application run.
+// StartDate and EndDate are the inclusive days of the run; From and Till are the
+// exact query range, with Till exclusive.
 type ExecutionPlan struct {
-	StartDate time.Time
-	EndDate   time.Time
-	Series    map[string]SeriesPlan
+	StartDate   time.Time
+	EndDate     time.Time
+	From        time.Time
+	Till        time.Time
+	DailyWindow TimeWindow
+	Series      map[string]SeriesPlan
+	Results     map[int]EventResult
+}
+
+// TimeWindow is a time-of-day range, given as offsets from midnight. Till is exclusive.
+// A window may wrap around midnight; the zero window covers the whole day.
+type TimeWindow struct {
+	From time.Duration
+	Till time.Duration
+}
+
+// IsZero reports whether the window covers the whole day.
+func (w TimeWindow) IsZero() bool {
+	return w.From == w.Till
+}
+
+// Contains reports whether the time of day of t lies within the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	if w.IsZero() {
+		return true
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.From < w.Till {
+		return offset >= w.From && offset < w.Till
+	}
+	return offset >= w.From || offset < w.Till
+}
+
+// Result returns the recorded result for an event, or a planned result if the run did not reach it.
+func (p ExecutionPlan) Result(eventID int) EventResult {
+	if result, ok := p.Results[eventID]; ok {
+		return result
+	}
+	return EventResult{Outcome: OutcomePlanned, Recording: RecordingUnknown}
+}
+
+// UploadOutcome is what a run did with one event.
+type UploadOutcome string
+
+const (
+	OutcomePlanned  UploadOutcome = "planned"
+	OutcomeUploaded UploadOutcome = "uploaded"
+	OutcomeSkipped  UploadOutcome = "skipped"
+	OutcomeFailed   UploadOutcome = "failed"
+	// OutcomeDeleted marks an uploaded recording that a rollback removed again.
+	OutcomeDeleted UploadOutcome = "deleted"
+)
+
+// RecordingState is whether an event had an active recording before the run.
+type RecordingState string
+
+const (
+	RecordingUnknown RecordingState = "unknown"
+	RecordingNone    RecordingState = "none"
+	RecordingActive  RecordingState = "active"
+)
+
+// recordingState converts the answer of a recording check.
+func recordingState(hasRecording bool) RecordingState {
+	if hasRecording {
+		return RecordingActive
+	}
+	return RecordingNone
+}
+
+// EventResult records the outcome for one event, the reason for a skip or failure,
+// and the recording state found before the upload.
+type EventResult struct {
+	Outcome   UploadOutcome
+	Reason    string
+	Recording RecordingState
+}
+
+// NewEventResult returns a result for an event whose recording state has been checked.
+func NewEventResult(outcome UploadOutcome, reason string, hasRecording bool) EventResult {
+	return EventResult{Outcome: outcome, Reason: reason, Recording: recordingState(hasRecording)}
 }
@@ -2,12 +2,14 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/johannes-kuhfuss/calcmsfeeder/app"
 )
 
 func main() {
 	if err := app.RunApp(); err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(app.ExitCode(err))
 	}
 }
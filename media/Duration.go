@@ -0,0 +1,292 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// mp3SearchLimit bounds the search for the first MPEG frame after the ID3 tag.
+const mp3SearchLimit = 64 << 10
+
+// oggTailSize is the number of trailing bytes searched for the last Ogg page.
+const oggTailSize = 64 << 10
+
+var errNoDuration = errors.New("duration not found in file headers")
+
+// FileDuration opens an audio file and reads its playing time from the headers.
+func FileDuration(path string, format Format) (time.Duration, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return ReadDuration(file, info.Size(), format)
+}
+
+// ReadDuration reads the playing time of an audio file from its headers.
+func ReadDuration(r io.ReaderAt, size int64, format Format) (time.Duration, error) {
+	switch format {
+	case FormatMP3:
+		return mp3Duration(r, size)
+	case FormatWAV:
+		return wavDuration(r, size)
+	case FormatFLAC:
+		return flacDuration(r)
+	case FormatOGG:
+		return oggDuration(r, size)
+	}
+	return 0, fmt.Errorf("no duration for %q files", format)
+}
+
+func samplesToDuration(samples, rate uint64) time.Duration {
+	if rate == 0 {
+		return 0
+	}
+	return time.Duration(samples/rate*uint64(time.Second)) + time.Duration(samples%rate*uint64(time.Second)/rate)
+}
+
+func readAt(r io.ReaderAt, offset int64, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	read, err := r.ReadAt(buf, offset)
+	if err != nil && !(errors.Is(err, io.EOF) && read > 0) {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+var (
+	mpegBitrates = map[[2]byte][16]int{
+		{3, 3}: {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{3, 2}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{3, 1}: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{2, 3}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{2, 2}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{2, 1}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	mpegSampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000},
+		2: {22050, 24000, 16000},
+		0: {11025, 12000, 8000},
+	}
+)
+
+// mpegFrame is a decoded MPEG audio frame header.
+type mpegFrame struct {
+	version    byte // 3 = MPEG 1, 2 = MPEG 2, 0 = MPEG 2.5
+	layer      byte // 3 = Layer I, 2 = Layer II, 1 = Layer III
+	bitrate    int  // kbit/s
+	sampleRate int
+	padding    int
+	mono       bool
+}
+
+func parseMPEGFrame(header []byte) (mpegFrame, bool) {
+	if len(header) < 4 || !isMPEGFrameHeader(header) {
+		return mpegFrame{}, false
+	}
+	frame := mpegFrame{
+		version: (header[1] >> 3) & 0x03,
+		layer:   (header[1] >> 1) & 0x03,
+		padding: int(header[2]>>1) & 0x01,
+		mono:    header[3]>>6 == 0x03,
+	}
+	tableVersion := frame.version
+	if tableVersion == 0 {
+		tableVersion = 2
+	}
+	frame.bitrate = mpegBitrates[[2]byte{tableVersion, frame.layer}][header[2]>>4]
+	frame.sampleRate = mpegSampleRates[frame.version][(header[2]>>2)&0x03]
+	return frame, frame.bitrate > 0
+}
+
+func (f mpegFrame) samplesPerFrame() int {
+	switch {
+	case f.layer == 3:
+		return 384
+	case f.layer == 1 && f.version != 3:
+		return 576
+	}
+	return 1152
+}
+
+func (f mpegFrame) length() int {
+	if f.layer == 3 {
+		return (12*f.bitrate*1000/f.sampleRate + f.padding) * 4
+	}
+	return f.samplesPerFrame()/8*f.bitrate*1000/f.sampleRate + f.padding
+}
+
+// sideInfoSize is the size of the Layer III side information that precedes a Xing header.
+func (f mpegFrame) sideInfoSize() int {
+	switch {
+	case f.version == 3 && f.mono:
+		return 17
+	case f.version == 3:
+		return 32
+	case f.mono:
+		return 9
+	}
+	return 17
+}
+
+// mp3Duration uses the Xing/Info or VBRI frame count if present and falls back to the constant bitrate.
+func mp3Duration(r io.ReaderAt, size int64) (time.Duration, error) {
+	tag, err := readAt(r, 0, 10)
+	if err != nil {
+		return 0, err
+	}
+	start := id3v2Length(tag)
+	buf, err := readAt(r, start, mp3SearchLimit)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i+4 <= len(buf); i++ {
+		frame, ok := parseMPEGFrame(buf[i:])
+		if !ok {
+			continue
+		}
+		if next := i + frame.length(); next+4 <= len(buf) {
+			if _, ok := parseMPEGFrame(buf[next:]); !ok {
+				continue
+			}
+		}
+		if frames, ok := mp3FrameCount(buf[i:], frame); ok {
+			return samplesToDuration(uint64(frames)*uint64(frame.samplesPerFrame()), uint64(frame.sampleRate)), nil
+		}
+		audioBytes := size - start - int64(i)
+		if trailer, err := readAt(r, size-128, 3); err == nil && bytes.Equal(trailer, []byte("TAG")) {
+			audioBytes -= 128
+		}
+		return bitsDuration(audioBytes*8, int64(frame.bitrate*1000)), nil
+	}
+	return 0, fmt.Errorf("MP3: %w", errNoDuration)
+}
+
+// bitsDuration returns the playing time of bits at bitsPerSecond without overflowing for
+// files of several gigabytes.
+func bitsDuration(bits, bitsPerSecond int64) time.Duration {
+	seconds := bits / bitsPerSecond
+	remainder := bits % bitsPerSecond
+	return time.Duration(seconds)*time.Second + time.Duration(remainder*int64(time.Second)/bitsPerSecond)
+}
+
+// id3v2Length returns the length of the ID3v2 tag starting with header, including header and
+// footer, or 0 if header is no ID3v2 header.
+func id3v2Length(header []byte) int64 {
+	if len(header) < 10 || !bytes.HasPrefix(header, []byte("ID3")) {
+		return 0
+	}
+	length := 10 + (int64(header[6]&0x7F)<<21 | int64(header[7]&0x7F)<<14 | int64(header[8]&0x7F)<<7 | int64(header[9]&0x7F))
+	if header[5]&0x10 != 0 {
+		length += 10
+	}
+	return length
+}
+
+// mp3FrameCount reads the total number of frames from a Xing/Info or VBRI header in the first frame.
+func mp3FrameCount(frameData []byte, frame mpegFrame) (uint32, bool) {
+	xing := 4 + frame.sideInfoSize()
+	if xing+12 <= len(frameData) {
+		id := frameData[xing : xing+4]
+		if (bytes.Equal(id, []byte("Xing")) || bytes.Equal(id, []byte("Info"))) && frameData[xing+7]&0x01 != 0 {
+			return binary.BigEndian.Uint32(frameData[xing+8:]), true
+		}
+	}
+	const vbri = 4 + 32
+	if vbri+18 <= len(frameData) && bytes.Equal(frameData[vbri:vbri+4], []byte("VBRI")) {
+		return binary.BigEndian.Uint32(frameData[vbri+14:]), true
+	}
+	return 0, false
+}
+
+// wavDuration divides the size of the data chunk by the byte rate from the fmt chunk.
+func wavDuration(r io.ReaderAt, size int64) (time.Duration, error) {
+	var byteRate uint32
+	for offset := int64(12); offset+8 <= size; {
+		header, err := readAt(r, offset, 8)
+		if err != nil || len(header) < 8 {
+			return 0, fmt.Errorf("WAV: read chunk header: %w", errNoDuration)
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+		switch string(header[0:4]) {
+		case "fmt ":
+			fmtChunk, err := readAt(r, offset+8, 16)
+			if err != nil || len(fmtChunk) < 16 {
+				return 0, fmt.Errorf("WAV: truncated fmt chunk")
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtChunk[8:12])
+		case "data":
+			if byteRate == 0 {
+				return 0, fmt.Errorf("WAV: data chunk before fmt chunk")
+			}
+			if remaining := size - offset - 8; chunkSize > remaining {
+				chunkSize = remaining
+			}
+			return samplesToDuration(uint64(chunkSize), uint64(byteRate)), nil
+		}
+		offset += 8 + chunkSize + chunkSize%2
+	}
+	return 0, fmt.Errorf("WAV: %w", errNoDuration)
+}
+
+// flacDuration reads the sample rate and total samples from the STREAMINFO block.
+func flacDuration(r io.ReaderAt) (time.Duration, error) {
+	header, err := readAt(r, 0, 4+4+34)
+	if err != nil {
+		return 0, err
+	}
+	if len(header) < 42 || header[4]&0x7F != 0 {
+		return 0, fmt.Errorf("FLAC: missing STREAMINFO block")
+	}
+	info := header[8:]
+	sampleRate := uint64(info[10])<<12 | uint64(info[11])<<4 | uint64(info[12])>>4
+	totalSamples := uint64(info[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(info[14:18]))
+	if sampleRate == 0 || totalSamples == 0 {
+		return 0, fmt.Errorf("FLAC: %w", errNoDuration)
+	}
+	return samplesToDuration(totalSamples, sampleRate), nil
+}
+
+// oggDuration divides the granule position of the last page by the rate from the identification header.
+func oggDuration(r io.ReaderAt, size int64) (time.Duration, error) {
+	first, err := readAt(r, 0, 27+255+19)
+	if err != nil {
+		return 0, err
+	}
+	if len(first) < 27 {
+		return 0, fmt.Errorf("OGG: truncated page")
+	}
+	packet := first[27+int(first[26]):]
+	var rate, preSkip uint64
+	switch {
+	case len(packet) >= 16 && bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		rate = uint64(binary.LittleEndian.Uint32(packet[12:16]))
+	case len(packet) >= 12 && bytes.HasPrefix(packet, []byte("OpusHead")):
+		rate, preSkip = 48000, uint64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return 0, fmt.Errorf("OGG: unsupported codec, want Vorbis or Opus")
+	}
+	tailStart := max(size-oggTailSize, 0)
+	tail, err := readAt(r, tailStart, int(size-tailStart))
+	if err != nil {
+		return 0, err
+	}
+	last := bytes.LastIndex(tail, []byte("OggS"))
+	if last < 0 || last+14 > len(tail) {
+		return 0, fmt.Errorf("OGG: %w", errNoDuration)
+	}
+	granule := binary.LittleEndian.Uint64(tail[last+6 : last+14])
+	if granule <= preSkip || rate == 0 {
+		return 0, fmt.Errorf("OGG: %w", errNoDuration)
+	}
+	return samplesToDuration(granule-preSkip, rate), nil
+}
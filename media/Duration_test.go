@@ -0,0 +1,147 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func mp3TestFrames(count int, firstFrame func([]byte)) []byte {
+	return mp3TestFile(10, count, firstFrame)
+}
+
+// mp3TestFile returns an ID3v2 tag holding tagSize bytes followed by count frames.
+func mp3TestFile(tagSize byte, count int, firstFrame func([]byte)) []byte {
+	frame := make([]byte, 417) // MPEG 1 Layer III, 128 kbit/s, 44.1 kHz, no padding
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	var data bytes.Buffer
+	data.WriteString("ID3\x04\x00\x00\x00\x00\x00")
+	data.WriteByte(tagSize)
+	data.Write(make([]byte, tagSize))
+	for i := 0; i < count; i++ {
+		f := append([]byte(nil), frame...)
+		if i == 0 && firstFrame != nil {
+			firstFrame(f)
+		}
+		data.Write(f)
+	}
+	return data.Bytes()
+}
+
+func wavTestFile(byteRate uint32, dataSize int) []byte {
+	var data bytes.Buffer
+	data.WriteString("RIFF\x00\x00\x00\x00WAVE")
+	data.WriteString("LIST\x03\x00\x00\x00abc\x00")
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:], byteRate)
+	binary.LittleEndian.PutUint32(fmtChunk[8:], byteRate)
+	data.WriteString("fmt \x10\x00\x00\x00")
+	data.Write(fmtChunk)
+	data.WriteString("data")
+	binary.Write(&data, binary.LittleEndian, uint32(dataSize))
+	data.Write(make([]byte, dataSize))
+	return data.Bytes()
+}
+
+func flacTestFile(sampleRate, totalSamples uint64) []byte {
+	info := make([]byte, 34)
+	info[10] = byte(sampleRate >> 12)
+	info[11] = byte(sampleRate >> 4)
+	info[12] = byte(sampleRate<<4) | 0x02
+	info[13] = 0xF0 | byte(totalSamples>>32)
+	binary.BigEndian.PutUint32(info[14:], uint32(totalSamples))
+	return append([]byte("fLaC\x80\x00\x00\x22"), info...)
+}
+
+func oggTestPage(granule uint64, packet []byte) []byte {
+	page := []byte("OggS\x00\x02")
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	page = append(page, make([]byte, 12)...)
+	page = append(page, 1, byte(len(packet)))
+	return append(page, packet...)
+}
+
+func TestReadDuration(t *testing.T) {
+	vorbisHeader := []byte("\x01vorbis\x00\x00\x00\x00\x02")
+	vorbisHeader = binary.LittleEndian.AppendUint32(vorbisHeader, 48000)
+	vorbisHeader = append(vorbisHeader, make([]byte, 14)...)
+	opusHeader := []byte("OpusHead\x01\x02")
+	opusHeader = binary.LittleEndian.AppendUint16(opusHeader, 312)
+	opusHeader = append(opusHeader, make([]byte, 7)...)
+
+	tests := []struct {
+		name   string
+		format Format
+		data   []byte
+		want   time.Duration
+	}{
+		{name: "mp3 constant bitrate", format: FormatMP3, data: mp3TestFrames(100, nil), want: 2606250 * time.Microsecond},
+		{name: "mp3 short tag", format: FormatMP3, data: mp3TestFile(2, 100, nil), want: 2606250 * time.Microsecond},
+		{name: "mp3 xing", format: FormatMP3, data: mp3TestFrames(3, func(f []byte) {
+			copy(f[36:], "Xing\x00\x00\x00\x01")
+			binary.BigEndian.PutUint32(f[44:], 441)
+		}), want: 11520 * time.Millisecond},
+		{name: "mp3 vbri", format: FormatMP3, data: mp3TestFrames(3, func(f []byte) {
+			copy(f[36:], "VBRI")
+			binary.BigEndian.PutUint32(f[50:], 882)
+		}), want: 23040 * time.Millisecond},
+		{name: "wav", format: FormatWAV, data: wavTestFile(8000, 16000), want: 2 * time.Second},
+		{name: "flac", format: FormatFLAC, data: flacTestFile(44100, 441000*6), want: 60 * time.Second},
+		{name: "ogg vorbis", format: FormatOGG, data: append(oggTestPage(0, vorbisHeader), oggTestPage(48000*90, []byte{0})...), want: 90 * time.Second},
+		{name: "ogg opus", format: FormatOGG, data: append(oggTestPage(0, opusHeader), oggTestPage(48000*5+312, []byte{0})...), want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadDuration(bytes.NewReader(tt.data), int64(len(tt.data)), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("ReadDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadDurationRejectsMissingHeaders(t *testing.T) {
+	data := []byte("RIFF\x00\x00\x00\x00WAVEdata\x04\x00\x00\x00abcd")
+	if _, err := ReadDuration(bytes.NewReader(data), int64(len(data)), FormatWAV); err == nil {
+		t.Fatal("ReadDuration() accepted a WAV file without fmt chunk")
+	}
+	if _, err := ReadDuration(bytes.NewReader(nil), 0, FormatStream); err == nil {
+		t.Fatal("ReadDuration() returned a duration for a stream file")
+	}
+}
+
+func TestID3v2Length(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int64
+	}{
+		{header: "ID3\x04\x00\x00\x00\x00\x00\x02", want: 12},
+		{header: "ID3\x04\x00\x00\x00\x00\x00\x15", want: 31},
+		{header: "ID3\x04\x00\x00\x00\x00\x01\x7F", want: 265},
+		{header: "ID3\x04\x00\x10\x00\x00\x00\x02", want: 22},
+		{header: "\xFF\xFB\x90\x00\x00\x00\x00\x00\x00\x00", want: 0},
+	}
+	for _, tt := range tests {
+		if got := id3v2Length([]byte(tt.header)); got != tt.want {
+			t.Errorf("id3v2Length(%q) = %d, want %d", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMP3ConstantBitrateDurationOfLargeFile(t *testing.T) {
+	data := mp3TestFrames(100, nil)
+	size := int64(3 << 30)
+	got, err := ReadDuration(bytes.NewReader(data), size, FormatMP3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 201326590750 * time.Microsecond; got != want {
+		t.Fatalf("ReadDuration() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Format identifies the content type of an upload file.
+type Format string
+
+const (
+	FormatStream Format = "stream"
+	FormatMP3    Format = "MP3"
+	FormatWAV    Format = "WAV"
+	FormatFLAC   Format = "FLAC"
+	FormatOGG    Format = "OGG"
+)
+
+// SniffHeaderSize is the number of leading bytes SniffAudio needs to recognise a format.
+const SniffHeaderSize = 12
+
+// SniffAudio recognises MP3, WAV, FLAC and OGG files by their leading bytes.
+func SniffAudio(header []byte) (Format, bool) {
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return FormatFLAC, true
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return FormatOGG, true
+	case len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return FormatWAV, true
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return FormatMP3, true
+	case len(header) >= 4 && isMPEGFrameHeader(header):
+		return FormatMP3, true
+	}
+	return "", false
+}
+
+// isMPEGFrameHeader checks the sync word and the reserved bit patterns of an MPEG audio frame header.
+func isMPEGFrameHeader(header []byte) bool {
+	if header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return false
+	}
+	version := (header[1] >> 3) & 0x03
+	layer := (header[1] >> 1) & 0x03
+	bitrate := header[2] >> 4
+	sampleRate := (header[2] >> 2) & 0x03
+	return version != 0x01 && layer != 0x00 && bitrate != 0x0F && sampleRate != 0x03
+}
+
+// ParseStreamFile parses and validates the content of an mAirlist stream pointer file.
+func ParseStreamFile(data []byte) (StreamFile, error) {
+	var file StreamFile
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#"):
+			if id, ok := parseStreamIDComment(line); ok {
+				file.StreamID = id
+			}
+		case file.URL == "":
+			file.URL = line
+		default:
+			return StreamFile{}, fmt.Errorf("unexpected line %q after stream URL", line)
+		}
+	}
+	if file.StreamID == "" {
+		return StreamFile{}, fmt.Errorf("missing %q comment", strings.TrimSpace(streamIDComment))
+	}
+	if file.URL == "" {
+		return StreamFile{}, fmt.Errorf("missing stream URL")
+	}
+	if err := file.Validate(); err != nil {
+		return StreamFile{}, err
+	}
+	return file, nil
+}
+
+func parseStreamIDComment(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, strings.TrimSpace(streamIDComment))
+	if !ok {
+		return "", false
+	}
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "++") || !strings.HasSuffix(rest, "++") || len(rest) < 5 {
+		return "", false
+	}
+	return rest[2 : len(rest)-2], true
+}
@@ -0,0 +1,62 @@
+package media
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSniffAudio(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   Format
+		ok     bool
+	}{
+		{name: "mp3 with ID3 tag", header: []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), want: FormatMP3, ok: true},
+		{name: "mp3 frame", header: []byte{0xFF, 0xFB, 0x90, 0x64}, want: FormatMP3, ok: true},
+		{name: "wav", header: []byte("RIFF\x24\x00\x00\x00WAVEfmt "), want: FormatWAV, ok: true},
+		{name: "flac", header: []byte("fLaC\x00\x00\x00\x22"), want: FormatFLAC, ok: true},
+		{name: "ogg", header: []byte("OggS\x00\x02"), want: FormatOGG, ok: true},
+		{name: "empty", header: nil},
+		{name: "text", header: []byte("stream contents")},
+		{name: "reserved mpeg bits", header: []byte{0xFF, 0xF9, 0xF0, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SniffAudio(tt.header)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("SniffAudio() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseStreamFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "generated file", content: "# Stream ID for mAirlist: ++radiozett++\nhttps://zett-stream.de:8000/zett.mp3\n"},
+		{name: "windows line endings", content: "# Stream ID for mAirlist: ++radiozett++\r\nhttps://zett-stream.de:8000/zett.mp3\r\n"},
+		{name: "empty", content: "", want: "missing"},
+		{name: "missing comment", content: "https://zett-stream.de/zett.mp3\n", want: "Stream ID for mAirlist"},
+		{name: "missing URL", content: "# Stream ID for mAirlist: ++radiozett++\n", want: "missing stream URL"},
+		{name: "invalid scheme", content: "# Stream ID for mAirlist: ++radiozett++\nrtmp://zett-stream.de/zett\n", want: "must use http or https"},
+		{name: "extra line", content: "# Stream ID for mAirlist: ++radiozett++\nhttps://a.example/\nhttps://b.example/\n", want: "unexpected line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseStreamFile([]byte(tt.content))
+			if tt.want == "" {
+				if err != nil || file.StreamID != "radiozett" {
+					t.Fatalf("ParseStreamFile() = %+v, %v", file, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Package media inspects and renders the files uploaded to calCMS.
+package media
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// StreamFileExtension is the file extension mAirlist uses for stream pointers.
+const StreamFileExtension = ".stream"
+
+const streamIDComment = "# Stream ID for mAirlist: "
+
+var streamIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// StreamFile is the content of an mAirlist stream pointer file.
+type StreamFile struct {
+	StreamID string
+	URL      string
+}
+
+// Render returns the two-line file content expected by mAirlist.
+func (f StreamFile) Render() []byte {
+	return []byte(fmt.Sprintf("%v++%v++\n%v\n", streamIDComment, f.StreamID, f.URL))
+}
+
+// FileName returns the base name of the generated file for the stream.
+func (f StreamFile) FileName() string {
+	return f.StreamID + StreamFileExtension
+}
+
+// Validate checks the stream ID and URL of a stream file.
+func (f StreamFile) Validate() error {
+	if err := ValidateStreamID(f.StreamID); err != nil {
+		return err
+	}
+	return ValidateStreamURL(f.URL)
+}
+
+// ValidateStreamID checks that a stream ID can be used in the mAirlist comment and as a file name.
+func ValidateStreamID(id string) error {
+	if !streamIDPattern.MatchString(id) {
+		return fmt.Errorf("stream ID %q must only contain letters, digits, '.', '_' or '-'", id)
+	}
+	return nil
+}
+
+// ValidateStreamURL checks that a stream URL is absolute and uses http or https.
+func ValidateStreamURL(raw string) error {
+	if strings.TrimSpace(raw) != raw || raw == "" {
+		return fmt.Errorf("stream URL %q must not be empty or padded with whitespace", raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("stream URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("stream URL %q must use http or https", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("stream URL %q has no host", raw)
+	}
+	return nil
+}
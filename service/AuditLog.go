@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+// maxAuditLineSize bounds a single audit entry when reading the log.
+const maxAuditLineSize = 1 << 20
+
+// Audit actions: an upload run adds recordings, a rollback deletes them.
+const (
+	AuditUpload = "upload"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records one change to calCMS. Entries are never changed once written.
+// PreviousPath is the recording that was active before an upload and UploadedPath the
+// recording the upload created; a rollback needs both to restore the previous state.
+type AuditEntry struct {
+	Time              time.Time             `json:"time"`
+	RunID             string                `json:"run_id"`
+	Action            string                `json:"action"`
+	RollbackOf        string                `json:"rollback_of,omitempty"`
+	OperatingUser     string                `json:"operating_user"`
+	Machine           string                `json:"machine"`
+	CmsUser           string                `json:"calcms_user"`
+	CmsHost           string                `json:"calcms_host"`
+	ProjectID         int                   `json:"project_id"`
+	StudioID          int                   `json:"studio_id"`
+	SeriesKey         string                `json:"series"`
+	SeriesID          int                   `json:"series_id"`
+	EventID           int                   `json:"event_id"`
+	File              string                `json:"file"`
+	SHA256            string                `json:"sha256"`
+	PreviousRecording domain.RecordingState `json:"previous_recording"`
+	PreviousPath      string                `json:"previous_recording_path,omitempty"`
+	UploadedPath      string                `json:"uploaded_recording_path,omitempty"`
+	Overwrite         bool                  `json:"overwrite"`
+	Result            domain.UploadOutcome  `json:"result"`
+	Error             string                `json:"error,omitempty"`
+}
+
+// AuditLog appends entries as JSON lines to a file that is only ever extended.
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenAuditLog opens or creates the audit log for appending.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &AuditLog{file: file}, nil
+}
+
+// Append writes one entry and syncs it to disk, so a crash cannot lose a recorded change.
+func (a *AuditLog) Append(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("sync audit log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the audit log file.
+func (a *AuditLog) Close() error {
+	return a.file.Close()
+}
+
+// AuditFilter selects audit entries. Zero fields match every entry; Till is exclusive.
+type AuditFilter struct {
+	RunID     string
+	From      time.Time
+	Till      time.Time
+	SeriesKey string
+	EventID   int
+}
+
+// Matches reports whether an entry passes the filter.
+func (f AuditFilter) Matches(entry AuditEntry) bool {
+	if f.RunID != "" && entry.RunID != f.RunID {
+		return false
+	}
+	if !f.From.IsZero() && entry.Time.Before(f.From) {
+		return false
+	}
+	if !f.Till.IsZero() && !entry.Time.Before(f.Till) {
+		return false
+	}
+	if f.SeriesKey != "" && entry.SeriesKey != f.SeriesKey {
+		return false
+	}
+	return f.EventID == 0 || entry.EventID == f.EventID
+}
+
+// ReadAuditLog returns the entries of the audit log at path that match filter, oldest first.
+func ReadAuditLog(path string, filter AuditFilter) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no audit log at %q", path)
+		}
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+	return readAuditEntries(file, filter)
+}
+
+func readAuditEntries(r io.Reader, filter AuditFilter) ([]AuditEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxAuditLineSize)
+	var entries []AuditEntry
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// FileSHA256 returns the hex-encoded SHA-256 checksum of a file.
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func TestAuditLogAppendsAndFilters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	entries := []AuditEntry{
+		{Time: time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC), RunID: "a", SeriesKey: "show", EventID: 1, Result: domain.OutcomeUploaded, PreviousRecording: domain.RecordingNone},
+		{Time: time.Date(2026, time.October, 2, 9, 0, 0, 0, time.UTC), RunID: "a", SeriesKey: "news", EventID: 2, Result: domain.OutcomeUploaded, PreviousRecording: domain.RecordingActive, Overwrite: true},
+		{Time: time.Date(2026, time.October, 3, 9, 0, 0, 0, time.UTC), RunID: "b", SeriesKey: "show", EventID: 3, Result: domain.OutcomeFailed, Error: "rejected"},
+	}
+	for _, batch := range [][]AuditEntry{entries[:2], entries[2:]} {
+		audit, err := OpenAuditLog(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range batch {
+			if err := audit.Append(entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := audit.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   string
+	}{
+		{name: "everything", want: "1 2 3"},
+		{name: "series", filter: AuditFilter{SeriesKey: "show"}, want: "1 3"},
+		{name: "event", filter: AuditFilter{EventID: 2}, want: "2"},
+		{name: "days", filter: AuditFilter{From: time.Date(2026, time.October, 2, 0, 0, 0, 0, time.UTC), Till: time.Date(2026, time.October, 3, 9, 0, 0, 0, time.UTC)}, want: "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadAuditLog(path, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, entry := range got {
+				ids = append(ids, strconv.Itoa(entry.EventID))
+			}
+			if strings.Join(ids, " ") != tt.want {
+				t.Fatalf("event IDs = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+	got, err := ReadAuditLog(path, AuditFilter{EventID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != entries[1] {
+		t.Fatalf("entry = %+v, want %+v", got[0], entries[1])
+	}
+}
+
+func TestReadAuditLogReportsBrokenLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte("{\"event_id\":1}\n\n{broken\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadAuditLog(path, AuditFilter{}); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("ReadAuditLog() error = %v, want line 3", err)
+	}
+	if _, err := ReadAuditLog(filepath.Join(t.TempDir(), "missing.jsonl"), AuditFilter{}); err == nil || !strings.Contains(err.Error(), "no audit log") {
+		t.Fatalf("ReadAuditLog() error = %v, want missing log", err)
+	}
+}
+
+func TestFileSHA256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("abc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := FileSHA256(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != want {
+		t.Fatalf("FileSHA256() = %s, want %s", got, want)
+	}
+}
@@ -2,7 +2,8 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"html"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/johannes-kuhfuss/calcmsfeeder/config"
@@ -23,10 +25,14 @@ import (
 )
 
 type CalCmsService interface {
-	QueryEvents(time.Time, time.Time) ([]domain.CalCMSEvent, error)
-	Login(string, string) error
-	HasRecording(int, int) (bool, error)
-	UploadFile(int, int, string) error
+	EventSource
+	Login(context.Context, string, string) error
+	HasRecording(context.Context, int, int) (bool, error)
+	Recordings(context.Context, int, int) ([]Recording, error)
+	UploadFile(context.Context, int, int, string) error
+	DeleteRecording(context.Context, int, int, string) error
+	ActivateRecording(context.Context, int, int, string) error
+	SeriesName(context.Context, int) (string, error)
 }
 
 var (
@@ -35,12 +41,29 @@ var (
 	htmlTag            = regexp.MustCompile(`(?s)<[^>]+>`)
 )
 
-const maxResponseSize int64 = 4 << 20
+const defaultMaxResponseSize int64 = 4 << 20
+
+// errUploadAnswered stops writing an upload body calCMS answered before reading it completely.
+var errUploadAnswered = errors.New("calCMS answered before the upload was complete")
+
+// UploadProgress describes how much of an upload request body has been sent.
+type UploadProgress struct {
+	EventID int
+	Sent    int64
+	Total   int64
+}
+
+// ProgressFunc receives upload progress. It is called from the goroutine sending the request.
+type ProgressFunc func(UploadProgress)
 
 // The calCms service handles all the communication with calCms and the necessary data transformation
 type DefaultCalCmsService struct {
-	Cfg    *config.AppConfig
-	client *http.Client
+	Cfg      *config.AppConfig
+	Progress ProgressFunc
+	// KeepEvent drops events from calCMS responses while they are decoded. Nil keeps all events.
+	KeepEvent EventFilter
+	client    *http.Client
+	limiter   *rateLimiter
 }
 
 // NewCalCmsService creates a new calCms service and injects its dependencies
@@ -51,20 +74,16 @@ func NewCalCmsService(cfg *config.AppConfig) *DefaultCalCmsService {
 // NewCalCmsServiceWithClient creates a service with an injected HTTP client.
 func NewCalCmsServiceWithClient(cfg *config.AppConfig, client *http.Client) *DefaultCalCmsService {
 	if client == nil {
-		timeout := cfg.CalCms.RequestTimeout
-		if timeout <= 0 {
-			timeout = 5 * time.Minute
-		}
-		client = &http.Client{Timeout: timeout}
+		client = newHTTPClient(cfg)
 	}
 	if client.Jar == nil {
 		client.Jar, _ = cookiejar.New(nil)
 	}
-	return &DefaultCalCmsService{Cfg: cfg, client: client}
+	return &DefaultCalCmsService{Cfg: cfg, client: client, limiter: newRateLimiter(int64(cfg.Uploads.Rate))}
 }
 
-// getCalCmsEventData retrieves the event information from calCms
-func (s *DefaultCalCmsService) getCalCmsEventData(startDate, endDate time.Time) ([]byte, error) {
+// getCalCmsEventData requests the events between from and till from calCms and returns the response body
+func (s *DefaultCalCmsService) getCalCmsEventData(ctx context.Context, from, till time.Time) (io.ReadCloser, error) {
 	//API doc: https://github.com/rapilodev/racalmas/blob/master/docs/event-api.md
 	//URL: https://programm.coloradio.org/agenda/events.cgi?from_date=2024-10-04&from_time=00:00&till_date=2024-10-05&till_time=00:00&template=event.json-p
 	calUrl, err := url.Parse(s.Cfg.CalCms.CmsHost)
@@ -73,57 +92,136 @@ func (s *DefaultCalCmsService) getCalCmsEventData(startDate, endDate time.Time)
 	}
 	calUrl = calUrl.JoinPath("agenda/events.cgi")
 	query := url.Values{}
-	query.Add("from_date", startDate.Format("2006-01-02"))
-	query.Add("from_time", "00:00")
-	query.Add("till_date", endDate.Format("2006-01-02"))
-	query.Add("till_time", "23:55")
+	loc := s.Cfg.TimeLocation()
+	query.Add("from_date", from.In(loc).Format("2006-01-02"))
+	query.Add("from_time", from.In(loc).Format("15:04"))
+	query.Add("till_date", till.In(loc).Format("2006-01-02"))
+	query.Add("till_time", till.In(loc).Format("15:04"))
 	query.Add("template", s.Cfg.CalCms.Template)
 	calUrl.RawQuery = query.Encode()
-	req, err := http.NewRequest(http.MethodGet, calUrl.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, calUrl.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("build calCMS HTTP request: %w", err)
 	}
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("execute calCMS HTTP request: %w", err)
+		return nil, &RequestError{Operation: "event query", Err: err, Timeout: operationTimeout(s.Cfg, s.Cfg.CalCms.QueryTimeout)}
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("calCMS returned HTTP %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, &HTTPStatusError{Operation: "event query", StatusCode: resp.StatusCode}
 	}
-	eventData, err := readLimitedBody(resp.Body, maxResponseSize)
-	if err != nil {
-		return nil, fmt.Errorf("read calCMS response: %w", err)
+	return resp.Body, nil
+}
+
+// QueryEvents retrieves the events starting at or after from and before till from calCMS.
+// Long ranges are split into CALCMS_QUERY_WINDOW_DAYS windows whose events are merged by event ID.
+// calCMS also returns events that merely overlap a window, so the result is filtered by event start;
+// events without a readable start time are kept as calCMS selected them.
+func (s *DefaultCalCmsService) QueryEvents(ctx context.Context, from, till time.Time) ([]domain.CalCMSEvent, error) {
+	windows := queryWindows(from, till, s.Cfg.CalCms.QueryWindowDays)
+	results := make([][]domain.CalCMSEvent, len(windows))
+	errs := make([]error, len(windows))
+	semaphore := make(chan struct{}, max(s.Cfg.CalCms.QueryParallelism, 1))
+	var wg sync.WaitGroup
+	for i, window := range windows {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i], errs[i] = s.queryEventWindow(ctx, window[0], window[1])
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			if len(windows) > 1 {
+				return nil, fmt.Errorf("query %v to %v: %w", windows[i][0].Format(time.DateTime), windows[i][1].Format(time.DateTime), err)
+			}
+			return nil, err
+		}
 	}
-	return eventData, nil
+	loc := s.Cfg.TimeLocation()
+	var events []domain.CalCMSEvent
+	for _, event := range mergeEvents(results) {
+		if start, err := event.Start(loc); err == nil && (start.Before(from) || !start.Before(till)) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
 }
 
-// QueryEvents retrieves the events in the inclusive date range from calCMS.
-func (s *DefaultCalCmsService) QueryEvents(startDate, endDate time.Time) ([]domain.CalCMSEvent, error) {
-	data, err := s.getCalCmsEventData(startDate, endDate)
-	if err != nil {
-		return nil, fmt.Errorf("get event data: %w", err)
+// queryWindows splits the range from..till into consecutive windows of at most days days.
+// A non-positive days value or an empty range keeps the range in one window.
+func queryWindows(from, till time.Time, days int) [][2]time.Time {
+	if days < 1 || !from.Before(till) {
+		return [][2]time.Time{{from, till}}
+	}
+	var windows [][2]time.Time
+	for start := from; start.Before(till); start = start.AddDate(0, 0, days) {
+		end := start.AddDate(0, 0, days)
+		if end.After(till) {
+			end = till
+		}
+		windows = append(windows, [2]time.Time{start, end})
 	}
-	var events domain.CalCMSEventResponse
-	if err := json.Unmarshal(data, &events); err != nil {
-		return nil, fmt.Errorf("decode calCMS response: %w", err)
+	return windows
+}
+
+// mergeEvents concatenates the window results and drops events already returned by an earlier window.
+func mergeEvents(results [][]domain.CalCMSEvent) []domain.CalCMSEvent {
+	seen := make(map[int]bool)
+	var events []domain.CalCMSEvent
+	for _, result := range results {
+		for _, event := range result {
+			if seen[event.EventID] {
+				continue
+			}
+			seen[event.EventID] = true
+			events = append(events, event)
+		}
 	}
-	return events.Events, nil
+	return events
 }
 
-func readLimitedBody(body io.Reader, maximum int64) ([]byte, error) {
-	data, err := io.ReadAll(io.LimitReader(body, maximum+1))
-	if err != nil {
+// queryEventWindow retrieves the events of one window with a single request, limited by CALCMS_QUERY_TIMEOUT.
+func (s *DefaultCalCmsService) queryEventWindow(ctx context.Context, from, till time.Time) ([]domain.CalCMSEvent, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	if int64(len(data)) > maximum {
-		return nil, fmt.Errorf("response exceeds %d bytes", maximum)
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout(s.Cfg, s.Cfg.CalCms.QueryTimeout))
+	defer cancel()
+	body, err := s.getCalCmsEventData(ctx, from, till)
+	if err != nil {
+		return nil, fmt.Errorf("get event data: %w", err)
+	}
+	defer body.Close()
+	events, err := decodeEventStream(newSizeLimitedReader(body, s.maxResponseSize()), s.KeepEvent)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnexpectedResponse, err)
 	}
-	return data, nil
+	return events, nil
+}
+
+// maxResponseSize returns the configured response size limit or the built-in default.
+func (s *DefaultCalCmsService) maxResponseSize() int64 {
+	if s.Cfg.CalCms.MaxResponseSize > 0 {
+		return int64(s.Cfg.CalCms.MaxResponseSize)
+	}
+	return defaultMaxResponseSize
+}
+
+func readLimitedBody(body io.Reader, maximum int64) ([]byte, error) {
+	return io.ReadAll(newSizeLimitedReader(body, maximum))
 }
 
 // Login logs into calCms and stores the session cookie for authentication of the upload request
-func (s *DefaultCalCmsService) Login(user, password string) error {
+func (s *DefaultCalCmsService) Login(ctx context.Context, user, password string) error {
+	timeout := operationTimeout(s.Cfg, s.Cfg.CalCms.LoginTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 	// POST to https://programm.coloradio.org/agenda/planung/calendar.cgi
 	// Content-Type application/x-www-form-urlencoded
 	// Form data: "user", "password", "authAction:login", "uri:"
@@ -138,34 +236,43 @@ func (s *DefaultCalCmsService) Login(user, password string) error {
 	form.Add("password", password)
 	form.Add("authAction", "login")
 	form.Add("uri", "")
-	req, err := http.NewRequest(http.MethodPost, calUrl.String(), strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, calUrl.String(), strings.NewReader(form.Encode()))
 	if err != nil {
 		return fmt.Errorf("build calCMS HTTP request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("execute calCMS login request: %w", err)
+		return &RequestError{Operation: "login", Err: err, Timeout: timeout}
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("calCMS login returned HTTP %d", resp.StatusCode)
+		return &HTTPStatusError{Operation: "login", StatusCode: resp.StatusCode}
 	}
 	if resp.Request == nil || resp.Request.Method != http.MethodPost || !sameEndpoint(resp.Request.URL, calUrl) {
-		return fmt.Errorf("calCMS login was redirected away from the login endpoint")
+		return fmt.Errorf("%w: login was redirected away from the login endpoint", ErrAuthentication)
 	}
 	uploadURL := calUrl.ResolveReference(&url.URL{Path: "audio-recordings.cgi"})
 	if len(s.client.Jar.Cookies(uploadURL)) == 0 {
-		return fmt.Errorf("calCMS login returned no session cookie")
+		return fmt.Errorf("%w: login returned no session cookie, check CALCMS_USER and CALCMS_PASS", ErrAuthentication)
 	}
 	return nil
 }
 
 // HasRecording reports whether calCMS already has an active recording for an event.
-func (s *DefaultCalCmsService) HasRecording(eventID, seriesID int) (bool, error) {
+func (s *DefaultCalCmsService) HasRecording(ctx context.Context, eventID, seriesID int) (bool, error) {
+	body, err := s.recordingsPage(ctx, eventID, seriesID)
+	if err != nil {
+		return false, err
+	}
+	return activeRecordingRow.Match(body), nil
+}
+
+// recordingsURL returns the audio recordings page of an event.
+func (s *DefaultCalCmsService) recordingsURL(eventID, seriesID int) (*url.URL, error) {
 	calURL, err := url.Parse(s.Cfg.CalCms.CmsHost)
 	if err != nil {
-		return false, fmt.Errorf("parse calCMS URL: %w", err)
+		return nil, fmt.Errorf("parse calCMS URL: %w", err)
 	}
 	calURL = calURL.JoinPath("agenda/planung/audio-recordings.cgi")
 	query := url.Values{}
@@ -174,26 +281,38 @@ func (s *DefaultCalCmsService) HasRecording(eventID, seriesID int) (bool, error)
 	query.Set("series_id", strconv.Itoa(seriesID))
 	query.Set("event_id", strconv.Itoa(eventID))
 	calURL.RawQuery = query.Encode()
-	req, err := http.NewRequest(http.MethodGet, calURL.String(), nil)
+	return calURL, nil
+}
+
+// recordingsPage fetches the audio recordings page of an event.
+func (s *DefaultCalCmsService) recordingsPage(ctx context.Context, eventID, seriesID int) ([]byte, error) {
+	timeout := operationTimeout(s.Cfg, s.Cfg.CalCms.CheckTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	calURL, err := s.recordingsURL(eventID, seriesID)
 	if err != nil {
-		return false, fmt.Errorf("build recording check request: %w", err)
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, calURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build recording check request: %w", err)
 	}
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return false, fmt.Errorf("execute recording check request: %w", err)
+		return nil, &RequestError{Operation: "recording check", Err: err, Timeout: timeout}
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("calCMS recording check returned HTTP %d", resp.StatusCode)
+		return nil, &HTTPStatusError{Operation: "recording check", StatusCode: resp.StatusCode}
 	}
 	if resp.Request != nil && !sameEndpoint(resp.Request.URL, calURL) {
-		return false, fmt.Errorf("calCMS recording check was redirected to %q", resp.Request.URL.Path)
+		return nil, fmt.Errorf("%w: recording check was redirected to %q", ErrSessionLost, resp.Request.URL.Path)
 	}
-	body, err := readLimitedBody(resp.Body, maxResponseSize)
+	body, err := readLimitedBody(resp.Body, s.maxResponseSize())
 	if err != nil {
-		return false, fmt.Errorf("read recording check response: %w", err)
+		return nil, fmt.Errorf("read recording check response: %w", err)
 	}
-	return activeRecordingRow.Match(body), nil
+	return body, nil
 }
 
 func sameEndpoint(left, right *url.URL) bool {
@@ -206,7 +325,7 @@ func sameEndpoint(left, right *url.URL) bool {
 }
 
 // UploadFile uploads a specified file to a specified event in a series
-func (s *DefaultCalCmsService) UploadFile(eventId int, seriesId int, uploadFile string) error {
+func (s *DefaultCalCmsService) UploadFile(ctx context.Context, eventId int, seriesId int, uploadFile string) error {
 	// Upload Page: https://programm.coloradio.org/agenda/planung/audio-recordings.cgi?project_id=1&studio_id=1&series_id=395&event_id=37901
 	// POST request
 	// Cookie set sessionID
@@ -218,16 +337,20 @@ func (s *DefaultCalCmsService) UploadFile(eventId int, seriesId int, uploadFile
 	calUrl = calUrl.JoinPath("agenda/planung/audio-recordings.cgi")
 	file, err := os.Open(uploadFile)
 	if err != nil {
-		return fmt.Errorf("open upload file: %w", err)
+		return &FileError{Operation: "open upload file", Path: uploadFile, Err: err}
 	}
 	fileInfo, err := file.Stat()
 	if err != nil {
 		file.Close()
-		return fmt.Errorf("inspect upload file: %w", err)
+		return &FileError{Operation: "inspect upload file", Path: uploadFile, Err: err}
 	}
+	timeout := uploadTimeout(s.Cfg, fileInfo.Size())
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 	reader, writer := io.Pipe()
-	multipartWriter := multipart.NewWriter(writer)
-	req, err := http.NewRequest(http.MethodPost, calUrl.String(), reader)
+	multipartWriter := multipart.NewWriter(s.limiter.writer(writer))
+	body := &progressReader{reader: reader, report: s.Progress, progress: UploadProgress{EventID: eventId}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, calUrl.String(), body)
 	if err != nil {
 		file.Close()
 		reader.Close()
@@ -243,6 +366,7 @@ func (s *DefaultCalCmsService) UploadFile(eventId int, seriesId int, uploadFile
 		return fmt.Errorf("calculate upload size: %w", err)
 	}
 	req.ContentLength = contentLength
+	body.progress.Total = contentLength
 	writeDone := make(chan error, 1)
 	go func() {
 		defer file.Close()
@@ -258,36 +382,69 @@ func (s *DefaultCalCmsService) UploadFile(eventId int, seriesId int, uploadFile
 	if err != nil {
 		reader.CloseWithError(err)
 		<-writeDone
-		return fmt.Errorf("execute calCMS upload request: %w", err)
+		return &RequestError{Operation: "upload", Err: err, Timeout: timeout}
 	}
 	defer resp.Body.Close()
-	if err := <-writeDone; err != nil {
-		return err
-	}
+	// calCMS or a proxy may answer before reading the whole body; stop writing the rest.
+	reader.CloseWithError(errUploadAnswered)
+	writeErr := <-writeDone
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("calCMS upload returned HTTP %d", resp.StatusCode)
+		return &HTTPStatusError{Operation: "upload", StatusCode: resp.StatusCode}
+	}
+	if writeErr != nil {
+		return writeErr
 	}
 	if resp.Request == nil || resp.Request.Method != http.MethodPost || !sameEndpoint(resp.Request.URL, calUrl) {
 		redirectPath := "unknown endpoint"
 		if resp.Request != nil && resp.Request.URL != nil {
 			redirectPath = resp.Request.URL.Path
 		}
-		return fmt.Errorf("calCMS upload was redirected to %q", redirectPath)
+		return fmt.Errorf("%w: upload was redirected to %q", ErrSessionLost, redirectPath)
 	}
-	responseBody, err := readLimitedBody(resp.Body, maxResponseSize)
+	responseBody, err := readLimitedBody(resp.Body, s.maxResponseSize())
 	if err != nil {
 		return fmt.Errorf("read calCMS upload response: %w", err)
 	}
-	if match := uploadErrorRow.FindSubmatch(responseBody); len(match) == 2 {
-		message := strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(string(match[1]), " ")))
-		if message == "" {
-			message = "unknown server-side error"
-		}
-		return fmt.Errorf("calCMS rejected upload: %s", message)
+	if message, ok := serverErrorMessage(responseBody); ok {
+		return &RejectedError{Operation: "upload", Message: message}
 	}
 	return nil
 }
 
+// serverErrorMessage extracts the error message calCMS shows on a page, if any.
+func serverErrorMessage(body []byte) (string, bool) {
+	match := uploadErrorRow.FindSubmatch(body)
+	if len(match) != 2 {
+		return "", false
+	}
+	message := strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(string(match[1]), " ")))
+	if message == "" {
+		message = "unknown server-side error"
+	}
+	return message, true
+}
+
+// progressReader reports the bytes the HTTP client has read from the request body. Closing
+// it closes the pipe, so the writer stops when the client gives up on the body.
+type progressReader struct {
+	reader   *io.PipeReader
+	report   ProgressFunc
+	progress UploadProgress
+}
+
+func (r *progressReader) Read(data []byte) (int, error) {
+	n, err := r.reader.Read(data)
+	if n > 0 && r.report != nil {
+		r.progress.Sent += int64(n)
+		r.report(r.progress)
+	}
+	return n, err
+}
+
+func (r *progressReader) Close() error {
+	return r.reader.Close()
+}
+
 type countingWriter struct {
 	n int64
 }
@@ -1,12 +1,16 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -31,8 +35,8 @@ func TestQueryAndFilterEvents(t *testing.T) {
 		wantQuery := map[string]string{
 			"from_date": "2026-07-21",
 			"from_time": "00:00",
-			"till_date": "2026-07-27",
-			"till_time": "23:55",
+			"till_date": "2026-07-28",
+			"till_time": "00:00",
 			"template":  "events.json",
 		}
 		for key, want := range wantQuery {
@@ -47,7 +51,7 @@ func TestQueryAndFilterEvents(t *testing.T) {
 
 	cfg := serviceTestConfig(server.URL)
 	svc := NewCalCmsServiceWithClient(cfg, server.Client())
-	events, err := svc.QueryEvents(time.Date(2026, time.July, 21, 0, 0, 0, 0, time.UTC), time.Date(2026, time.July, 27, 0, 0, 0, 0, time.UTC))
+	events, err := svc.QueryEvents(t.Context(), time.Date(2026, time.July, 21, 0, 0, 0, 0, time.UTC), time.Date(2026, time.July, 28, 0, 0, 0, 0, time.UTC))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -56,6 +60,102 @@ func TestQueryAndFilterEvents(t *testing.T) {
 	}
 }
 
+func TestQueryEventsCoversWholeLastDay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"events":[
+			{"event_id":1,"skey":"show","start_datetime":"2026-07-20T23:00:00","end_datetime":"2026-07-21T01:00:00"},
+			{"event_id":2,"skey":"show","start_datetime":"2026-07-21T00:00:00"},
+			{"event_id":3,"skey":"show","start_datetime":"2026-07-27T23:58:00"},
+			{"event_id":4,"skey":"show","start_datetime":"2026-07-27T23:00:00","end_datetime":"2026-07-28T01:00:00"},
+			{"event_id":5,"skey":"show","start_datetime":"2026-07-28T00:00:00"}
+		]}`)
+	}))
+	defer server.Close()
+	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
+	start := time.Date(2026, time.July, 21, 0, 0, 0, 0, time.Local)
+	events, err := svc.QueryEvents(t.Context(), start, start.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, event := range events {
+		ids = append(ids, event.EventID)
+	}
+	if fmt.Sprint(ids) != "[2 3 4]" {
+		t.Fatalf("event IDs = %v, want [2 3 4]", ids)
+	}
+}
+
+func TestQueryUsesConfiguredTimezoneForDates(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("from_date"); got != "2026-10-25" {
+			t.Errorf("from_date = %q, want 2026-10-25", got)
+		}
+		if got := r.URL.Query().Get("till_date"); got != "2026-10-26" {
+			t.Errorf("till_date = %q, want 2026-10-26", got)
+		}
+		io.WriteString(w, `{"events":[]}`)
+	}))
+	defer server.Close()
+	cfg := serviceTestConfig(server.URL)
+	cfg.Location = berlin
+	svc := NewCalCmsServiceWithClient(cfg, server.Client())
+	// Midnight in Berlin on the day of the autumn DST change, passed as UTC instants.
+	start := time.Date(2026, time.October, 25, 0, 0, 0, 0, berlin).UTC()
+	end := time.Date(2026, time.October, 26, 0, 0, 0, 0, berlin).UTC()
+	if _, err := svc.QueryEvents(t.Context(), start, end); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryEventsSplitsLongRanges(t *testing.T) {
+	var mu sync.Mutex
+	windows := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, till := r.URL.Query().Get("from_date"), r.URL.Query().Get("till_date")
+		mu.Lock()
+		windows[from] = till
+		mu.Unlock()
+		switch from {
+		case "2026-07-01":
+			io.WriteString(w, `{"events":[{"event_id":1,"skey":"show"},{"event_id":2,"skey":"show"}]}`)
+		case "2026-07-08":
+			io.WriteString(w, `{"events":[{"event_id":2,"skey":"show"},{"event_id":3,"skey":"show"}]}`)
+		default:
+			io.WriteString(w, `{"events":[{"event_id":4,"skey":"show"}]}`)
+		}
+	}))
+	defer server.Close()
+	cfg := serviceTestConfig(server.URL)
+	cfg.CalCms.QueryWindowDays = 7
+	cfg.CalCms.QueryParallelism = 3
+	svc := NewCalCmsServiceWithClient(cfg, server.Client())
+	events, err := svc.QueryEvents(t.Context(), time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.July, 18, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWindows := map[string]string{"2026-07-01": "2026-07-08", "2026-07-08": "2026-07-15", "2026-07-15": "2026-07-18"}
+	if len(windows) != len(wantWindows) {
+		t.Fatalf("windows = %v, want %v", windows, wantWindows)
+	}
+	for from, till := range wantWindows {
+		if windows[from] != till {
+			t.Fatalf("windows = %v, want %v", windows, wantWindows)
+		}
+	}
+	var ids []int
+	for _, event := range events {
+		ids = append(ids, event.EventID)
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4]" {
+		t.Fatalf("event IDs = %v, want [1 2 3 4]", ids)
+	}
+}
+
 func TestMalformedJSONDoesNotPoisonSubsequentQuery(t *testing.T) {
 	var requests atomic.Int32
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -68,10 +168,10 @@ func TestMalformedJSONDoesNotPoisonSubsequentQuery(t *testing.T) {
 	defer server.Close()
 	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
 	start, end := time.Now(), time.Now()
-	if _, err := svc.QueryEvents(start, end); err == nil {
+	if _, err := svc.QueryEvents(t.Context(), start, end); err == nil {
 		t.Fatal("first query unexpectedly succeeded")
 	}
-	if _, err := svc.QueryEvents(start, end); err != nil {
+	if _, err := svc.QueryEvents(t.Context(), start, end); err != nil {
 		t.Fatalf("second query failed: %v", err)
 	}
 }
@@ -82,18 +182,78 @@ func TestQueryRejectsOversizedResponse(t *testing.T) {
 	}))
 	defer server.Close()
 	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
-	_, err := svc.QueryEvents(time.Now(), time.Now())
+	_, err := svc.QueryEvents(t.Context(), time.Now(), time.Now())
 	if err == nil || !strings.Contains(err.Error(), "exceeds") {
 		t.Fatalf("QueryEvents() error = %v, want response size error", err)
 	}
 }
 
+func TestQueryHonorsConfiguredResponseLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{"events":[{"event_id":42,"skey":"show"},{"event_id":43,"skey":"show"}]}`)
+	}))
+	defer server.Close()
+	cfg := serviceTestConfig(server.URL)
+	cfg.CalCms.MaxResponseSize = 32
+	svc := NewCalCmsServiceWithClient(cfg, server.Client())
+	if _, err := svc.QueryEvents(t.Context(), time.Now(), time.Now()); err == nil || !strings.Contains(err.Error(), "exceeds 32 bytes") {
+		t.Fatalf("QueryEvents() error = %v, want response size error", err)
+	}
+}
+
 func TestConfiguredRequestTimeout(t *testing.T) {
 	cfg := serviceTestConfig("https://calendar.example")
 	cfg.CalCms.RequestTimeout = 17 * time.Second
+	cfg.CalCms.QueryTimeout = 30 * time.Second
 	svc := NewCalCmsService(cfg)
-	if svc.client.Timeout != 17*time.Second {
-		t.Fatalf("client timeout = %v, want 17s", svc.client.Timeout)
+	if svc.client.Timeout != 0 {
+		t.Fatalf("client timeout = %v, want none besides the operation timeouts", svc.client.Timeout)
+	}
+	if got := operationTimeout(cfg, cfg.CalCms.QueryTimeout); got != 30*time.Second {
+		t.Fatalf("query timeout = %v, want 30s", got)
+	}
+	if got := operationTimeout(cfg, cfg.CalCms.LoginTimeout); got != 17*time.Second {
+		t.Fatalf("login timeout = %v, want CALCMS_REQUEST_TIMEOUT", got)
+	}
+}
+
+func TestUploadTimeoutScalesWithFileSize(t *testing.T) {
+	cfg := serviceTestConfig("https://calendar.example")
+	cfg.CalCms.RequestTimeout = 5 * time.Minute
+	cfg.CalCms.UploadTimeout = time.Minute
+	tests := []struct {
+		name    string
+		minRate config.ByteRate
+		rate    config.ByteRate
+		want    time.Duration
+	}{
+		{name: "fixed", want: time.Minute},
+		{name: "minimum rate", minRate: 1 << 20, want: time.Minute + 100*time.Second},
+		{name: "lower upload limit", minRate: 1 << 20, rate: 512 << 10, want: time.Minute + 200*time.Second},
+		{name: "upload limit only", rate: 2 << 20, want: time.Minute + 50*time.Second},
+	}
+	for _, tt := range tests {
+		cfg.Uploads.MinRate, cfg.Uploads.Rate = tt.minRate, tt.rate
+		if got := uploadTimeout(cfg, 100<<20); got != tt.want {
+			t.Errorf("%v: upload timeout = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	cfg := serviceTestConfig(server.URL)
+	cfg.CalCms.RequestTimeout = time.Minute
+	cfg.CalCms.QueryTimeout = 50 * time.Millisecond
+	svc := NewCalCmsServiceWithClient(cfg, server.Client())
+	_, err := svc.QueryEvents(t.Context(), time.Now(), time.Now().Add(time.Hour))
+	if !errors.Is(err, ErrUnavailable) || !strings.Contains(err.Error(), "calCMS event query request timed out after 50ms") {
+		t.Fatalf("QueryEvents() error = %v, want timeout", err)
 	}
 }
 
@@ -159,21 +319,52 @@ func TestLoginAndUploadProtocol(t *testing.T) {
 	defer server.Close()
 
 	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
-	if err := svc.Login("alice", "s3cret"); err != nil {
+	if err := svc.Login(t.Context(), "alice", "s3cret"); err != nil {
 		t.Fatal(err)
 	}
-	hasRecording, err := svc.HasRecording(42, 99)
+	hasRecording, err := svc.HasRecording(t.Context(), 42, 99)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if !hasRecording {
 		t.Fatal("HasRecording() = false, want true")
 	}
-	if err := svc.UploadFile(42, 99, uploadFile); err != nil {
+	if err := svc.UploadFile(t.Context(), 42, 99, uploadFile); err != nil {
 		t.Fatal(err)
 	}
 }
 
+func TestUploadReportsProgress(t *testing.T) {
+	uploadFile := t.TempDir() + "/show.stream"
+	if err := os.WriteFile(uploadFile, []byte(strings.Repeat("a", 100<<10)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var received int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		atomic.StoreInt64(&received, n)
+	}))
+	defer server.Close()
+	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
+	var updates []UploadProgress
+	svc.Progress = func(p UploadProgress) { updates = append(updates, p) }
+	if err := svc.UploadFile(t.Context(), 42, 99, uploadFile); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) == 0 {
+		t.Fatal("no progress updates")
+	}
+	last := updates[len(updates)-1]
+	if last.EventID != 42 || last.Sent != last.Total || last.Total != atomic.LoadInt64(&received) {
+		t.Fatalf("last update = %+v, server received %d bytes", last, atomic.LoadInt64(&received))
+	}
+	for i := 1; i < len(updates); i++ {
+		if updates[i].Sent < updates[i-1].Sent {
+			t.Fatalf("progress went backwards: %+v", updates)
+		}
+	}
+}
+
 func TestUploadSurfacesCalCMSErrorResponse(t *testing.T) {
 	uploadFile := t.TempDir() + "/show.stream"
 	if err := os.WriteFile(uploadFile, []byte("audio stream"), 0o600); err != nil {
@@ -187,8 +378,9 @@ func TestUploadSurfacesCalCMSErrorResponse(t *testing.T) {
 	}))
 	defer server.Close()
 	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
-	err := svc.UploadFile(42, 99, uploadFile)
-	if err == nil || !strings.Contains(err.Error(), "Could not get file handle") {
+	err := svc.UploadFile(t.Context(), 42, 99, uploadFile)
+	var rejected *RejectedError
+	if !errors.As(err, &rejected) || !strings.Contains(err.Error(), "Could not get file handle") {
 		t.Fatalf("UploadFile() error = %v, want server error", err)
 	}
 }
@@ -203,12 +395,60 @@ func TestHasRecordingRejectsAuthenticationRedirect(t *testing.T) {
 	}))
 	defer server.Close()
 	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
-	_, err := svc.HasRecording(42, 99)
-	if err == nil || !strings.Contains(err.Error(), "redirected") {
+	_, err := svc.HasRecording(t.Context(), 42, 99)
+	if !errors.Is(err, ErrSessionLost) || !strings.Contains(err.Error(), "redirected") {
 		t.Fatalf("HasRecording() error = %v, want redirect error", err)
 	}
 }
 
+func TestUploadStopsWhenContextIsCancelled(t *testing.T) {
+	uploadFile := t.TempDir() + "/show.stream"
+	if err := os.WriteFile(uploadFile, []byte("audio stream"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
+	err := svc.UploadFile(ctx, 42, 99, uploadFile)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("UploadFile() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestUploadReturnsWhenServerAnswersEarly(t *testing.T) {
+	uploadFile := t.TempDir() + "/show.mp3"
+	file, err := os.Create(uploadFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Truncate(64 << 20); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer server.Close()
+	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
+	done := make(chan error, 1)
+	go func() { done <- svc.UploadFile(t.Context(), 42, 99, uploadFile) }()
+	select {
+	case err := <-done:
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Fatalf("UploadFile() error = %v, want status 413", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("UploadFile() did not return after an early response")
+	}
+}
+
 func TestUploadRejectsAuthenticationRedirect(t *testing.T) {
 	uploadFile := t.TempDir() + "/show.stream"
 	if err := os.WriteFile(uploadFile, []byte("audio stream"), 0o600); err != nil {
@@ -223,8 +463,8 @@ func TestUploadRejectsAuthenticationRedirect(t *testing.T) {
 	}))
 	defer server.Close()
 	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
-	err := svc.UploadFile(42, 99, uploadFile)
-	if err == nil || !strings.Contains(err.Error(), "redirected") {
+	err := svc.UploadFile(t.Context(), 42, 99, uploadFile)
+	if !errors.Is(err, ErrSessionLost) || !strings.Contains(err.Error(), "redirected") {
 		t.Fatalf("UploadFile() error = %v, want redirect error", err)
 	}
 }
@@ -233,7 +473,7 @@ func TestLoginRequiresSessionCookie(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
 	defer server.Close()
 	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
-	if err := svc.Login("alice", "secret"); err == nil || !strings.Contains(err.Error(), "no session cookie") {
+	if err := svc.Login(t.Context(), "alice", "secret"); !errors.Is(err, ErrAuthentication) || !strings.Contains(err.Error(), "no session cookie") {
 		t.Fatalf("Login() error = %v", err)
 	}
 }
@@ -248,8 +488,8 @@ func TestLoginRejectsAuthenticationRedirect(t *testing.T) {
 	}))
 	defer server.Close()
 	svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
-	err := svc.Login("alice", "secret")
-	if err == nil || !strings.Contains(err.Error(), "redirected") {
+	err := svc.Login(t.Context(), "alice", "secret")
+	if !errors.Is(err, ErrAuthentication) || !strings.Contains(err.Error(), "redirected") {
 		t.Fatalf("Login() error = %v, want redirect error", err)
 	}
 }
@@ -280,7 +520,7 @@ func TestHasRecording(t *testing.T) {
 			}))
 			defer server.Close()
 			svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
-			got, err := svc.HasRecording(42, 99)
+			got, err := svc.HasRecording(t.Context(), 42, 99)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -311,8 +551,8 @@ func TestNonOKResponseBodyIsClosed(t *testing.T) {
 		return &http.Response{StatusCode: http.StatusBadGateway, Body: body, Header: make(http.Header)}, nil
 	})}
 	svc := NewCalCmsServiceWithClient(serviceTestConfig("http://calendar.example"), client)
-	_, err := svc.QueryEvents(time.Now(), time.Now())
-	if err == nil || !strings.Contains(err.Error(), strconv.Itoa(http.StatusBadGateway)) {
+	_, err := svc.QueryEvents(t.Context(), time.Now(), time.Now())
+	if !errors.Is(err, ErrUnavailable) || !strings.Contains(err.Error(), strconv.Itoa(http.StatusBadGateway)) {
 		t.Fatalf("QueryEvents() error = %v", err)
 	}
 	if !body.closed.Load() {
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors for the failure classes callers need to tell apart.
+var (
+	// ErrAuthentication means calCMS did not accept the credentials.
+	ErrAuthentication = errors.New("calCMS authentication failed")
+	// ErrSessionLost means calCMS redirected a request away, usually to the login page.
+	ErrSessionLost = errors.New("calCMS session lost")
+	// ErrUnavailable means calCMS could not be reached or answered with a server error.
+	ErrUnavailable = errors.New("calCMS unavailable")
+	// ErrUnexpectedResponse means a calCMS response could not be understood.
+	ErrUnexpectedResponse = errors.New("unexpected calCMS response")
+)
+
+// HTTPStatusError reports a calCMS response with a status other than 200 OK.
+// Server errors also match ErrUnavailable.
+type HTTPStatusError struct {
+	Operation  string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("calCMS %v returned HTTP %d", e.Operation, e.StatusCode)
+}
+
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrUnavailable && e.StatusCode >= http.StatusInternalServerError
+}
+
+// RequestError reports a request that got no response, such as a network failure or
+// timeout. It matches ErrUnavailable and wraps the cause. Timeout is the limit the request had.
+type RequestError struct {
+	Operation string
+	Err       error
+	Timeout   time.Duration
+}
+
+func (e *RequestError) Error() string {
+	if e.Timeout > 0 && errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("calCMS %v request timed out after %v", e.Operation, e.Timeout)
+	}
+	return fmt.Sprintf("execute calCMS %v request: %v", e.Operation, e.Err)
+}
+
+func (e *RequestError) Unwrap() []error {
+	return []error{ErrUnavailable, e.Err}
+}
+
+// RejectedError reports a change calCMS refused with an error message on the result page.
+type RejectedError struct {
+	Operation string
+	Message   string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("calCMS rejected %v: %v", e.Operation, e.Message)
+}
+
+// FileError reports a problem with a local file, such as an unreadable upload file.
+type FileError struct {
+	Operation string
+	Path      string
+	Err       error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Operation, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+// snippetLength is the number of leading bytes of an unexpected response included in errors.
+const snippetLength = 120
+
+var utf8BOM = []byte("\xEF\xBB\xBF")
+
+// ErrResponseTooLarge is returned when a response exceeds the configured size limit.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// UnexpectedContentError reports an events response that does not contain JSON,
+// which usually means a wrong CALCMS_HOST or a maintenance page.
+type UnexpectedContentError struct {
+	Kind    string
+	Snippet string
+}
+
+func (e *UnexpectedContentError) Error() string {
+	return fmt.Sprintf("expected JSON events but got %v starting with %q", e.Kind, e.Snippet)
+}
+
+// EventFilter decides whether a decoded event is kept. It may be called concurrently.
+type EventFilter func(domain.CalCMSEvent) bool
+
+// decodeEventStream walks the events array of a response token by token and keeps
+// only the events accepted by keep, or all events if keep is nil. The response may
+// be wrapped in a JSON-P callback, start with a byte order mark, or have other text
+// in front of the JSON object; anything after the object is ignored.
+func decodeEventStream(body io.Reader, keep EventFilter) ([]domain.CalCMSEvent, error) {
+	reader := bufio.NewReader(body)
+	if err := skipToJSONObject(reader); err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(reader)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+	var events []domain.CalCMSEvent
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key, _ := token.(string); key != "events" {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := expectDelim(decoder, '['); err != nil {
+			return nil, fmt.Errorf("events: %w", err)
+		}
+		for decoder.More() {
+			var event domain.CalCMSEvent
+			if err := decoder.Decode(&event); err != nil {
+				return nil, fmt.Errorf("events: %w", err)
+			}
+			if keep == nil || keep(event) {
+				events = append(events, event)
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return nil, fmt.Errorf("events: %w", err)
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// skipToJSONObject advances the reader to the first '{' and reports HTML or other
+// non-JSON content with the start of the body.
+func skipToJSONObject(reader *bufio.Reader) error {
+	if prefix, err := reader.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		reader.Discard(len(utf8BOM))
+	}
+	var skipped []byte
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return &UnexpectedContentError{Kind: "non-JSON content", Snippet: responseSnippet(skipped)}
+		}
+		if err != nil {
+			return err
+		}
+		if b == '{' {
+			return reader.UnreadByte()
+		}
+		if b == '<' && len(bytes.TrimSpace(skipped)) == 0 {
+			head, _ := reader.Peek(snippetLength - 1)
+			return &UnexpectedContentError{Kind: "HTML", Snippet: responseSnippet(append([]byte{b}, head...))}
+		}
+		if len(skipped) < snippetLength {
+			skipped = append(skipped, b)
+		}
+	}
+}
+
+func expectDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %q but found %v", want, token)
+	}
+	return nil
+}
+
+// responseSnippet returns the start of a response body with whitespace collapsed.
+func responseSnippet(data []byte) string {
+	if len(data) > snippetLength {
+		data = data[:snippetLength]
+	}
+	for len(data) > 0 && !utf8.Valid(data) {
+		data = data[:len(data)-1]
+	}
+	return strings.Join(strings.Fields(string(data)), " ")
+}
+
+// sizeLimitedReader fails with ErrResponseTooLarge once more than remaining bytes were read.
+type sizeLimitedReader struct {
+	reader    io.Reader
+	remaining int64
+	limit     int64
+}
+
+func newSizeLimitedReader(reader io.Reader, limit int64) *sizeLimitedReader {
+	return &sizeLimitedReader{reader: reader, remaining: limit, limit: limit}
+}
+
+func (r *sizeLimitedReader) Read(data []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, fmt.Errorf("response exceeds %d bytes: %w", r.limit, ErrResponseTooLarge)
+	}
+	if int64(len(data)) > r.remaining+1 {
+		data = data[:r.remaining+1]
+	}
+	n, err := r.reader.Read(data)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return 0, fmt.Errorf("response exceeds %d bytes: %w", r.limit, ErrResponseTooLarge)
+	}
+	return n, err
+}
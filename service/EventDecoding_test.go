@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+func TestDecodeEventResponseToleratesWrappers(t *testing.T) {
+	payload := `{"events":[{"event_id":42,"skey":"show"}]}`
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "plain JSON", body: payload},
+		{name: "byte order mark", body: "\xEF\xBB\xBF" + payload},
+		{name: "JSON-P callback", body: "calcms_events(" + payload + ");"},
+		{name: "commented callback", body: "/**/ cb(\n" + payload + "\n)"},
+		{name: "prefix text", body: "while(1);\n" + payload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := decodeEventStream(strings.NewReader(tt.body), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 1 || events[0].EventID != 42 || events[0].Skey != "show" {
+				t.Fatalf("events = %+v", events)
+			}
+		})
+	}
+}
+
+func TestDecodeEventSeriesIDAsNumberOrString(t *testing.T) {
+	body := `{"events":[{"event_id":1,"series_id":"395","series_name":"Show"},{"event_id":2,"series_id":396},{"event_id":3,"series_id":""},{"event_id":4}]}`
+	events, err := decodeEventStream(strings.NewReader(body), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.LooseInt{395, 396, 0, 0}
+	for i, event := range events {
+		if event.SeriesID != want[i] {
+			t.Fatalf("event %d series ID = %v, want %v", event.EventID, event.SeriesID, want[i])
+		}
+	}
+	if events[0].SeriesName != "Show" {
+		t.Fatalf("series name = %q", events[0].SeriesName)
+	}
+	if _, err := decodeEventStream(strings.NewReader(`{"events":[{"series_id":"abc"}]}`), nil); err == nil {
+		t.Fatal("decodeEventStream() accepted a non-numeric series ID")
+	}
+}
+
+func TestDecodeEventResponseReportsHTML(t *testing.T) {
+	body := "\n<!DOCTYPE html>\n<html><head><title>Wartungsarbeiten</title></head><body>" + strings.Repeat("x", 200) + "</body></html>"
+	_, err := decodeEventStream(strings.NewReader(body), nil)
+	var contentErr *UnexpectedContentError
+	if !errors.As(err, &contentErr) {
+		t.Fatalf("error = %v, want UnexpectedContentError", err)
+	}
+	if contentErr.Kind != "HTML" || !strings.HasPrefix(contentErr.Snippet, "<!DOCTYPE html> <html><head><title>Wartungsarbeiten") || len(contentErr.Snippet) > snippetLength {
+		t.Fatalf("error = %+v", contentErr)
+	}
+	if _, err := decodeEventStream(strings.NewReader("Service Unavailable"), nil); !errors.As(err, &contentErr) {
+		t.Fatalf("error = %v, want UnexpectedContentError for plain text", err)
+	}
+}
+
+func TestDecodeEventStreamFiltersWhileDecoding(t *testing.T) {
+	body := `cb({"title":"agenda","meta":{"count":3,"series":["show","other"]},"events":[
+		{"event_id":1,"skey":"show"},
+		{"event_id":2,"skey":"other","title":"ignored"},
+		{"event_id":3,"skey":"show"}
+	],"footer":null});`
+	var seen []string
+	events, err := decodeEventStream(strings.NewReader(body), func(event domain.CalCMSEvent) bool {
+		seen = append(seen, event.Skey)
+		return event.Skey == "show"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].EventID != 1 || events[1].EventID != 3 {
+		t.Fatalf("events = %+v, want events 1 and 3", events)
+	}
+	if strings.Join(seen, ",") != "show,other,show" {
+		t.Fatalf("filter saw %v", seen)
+	}
+}
+
+func TestSizeLimitedReader(t *testing.T) {
+	if _, err := io.ReadAll(newSizeLimitedReader(strings.NewReader("12345"), 5)); err != nil {
+		t.Fatalf("reading exactly the limit failed: %v", err)
+	}
+	_, err := io.ReadAll(newSizeLimitedReader(strings.NewReader("123456"), 5))
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("error = %v, want ErrResponseTooLarge", err)
+	}
+}
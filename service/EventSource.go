@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+	"github.com/johannes-kuhfuss/calcmsfeeder/domain"
+)
+
+// EventSource provides the events starting at or after from and before till.
+type EventSource interface {
+	QueryEvents(context.Context, time.Time, time.Time) ([]domain.CalCMSEvent, error)
+}
+
+// eventDateTimeFormat is the calCMS start_datetime format used for events from other sources.
+const eventDateTimeFormat = "2006-01-02T15:04:05"
+
+// NewEventSource returns the configured event source. The calCMS service is used for EVENT_SOURCE=calcms.
+func NewEventSource(cfg *config.AppConfig, calCms EventSource) (EventSource, error) {
+	switch cfg.Events.Source {
+	case config.EventSourceCalCms:
+		return calCms, nil
+	case config.EventSourceJSON:
+		return &JSONFileEventSource{Path: cfg.Events.File, Location: cfg.TimeLocation()}, nil
+	case config.EventSourceICalendar:
+		return &ICalendarEventSource{Path: cfg.Events.File, Location: cfg.TimeLocation()}, nil
+	}
+	return nil, fmt.Errorf("unknown event source %q", cfg.Events.Source)
+}
+
+// JSONFileEventSource reads events from a saved calCMS events response.
+type JSONFileEventSource struct {
+	Path     string
+	Location *time.Location
+}
+
+// QueryEvents returns the events of the file that start within the range.
+func (s *JSONFileEventSource) QueryEvents(ctx context.Context, from, till time.Time) ([]domain.CalCMSEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open event file: %w", err)
+	}
+	defer file.Close()
+	events, err := decodeEventStream(file, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decode event file %q: %w", s.Path, err)
+	}
+	return eventsInRange(events, from, till, s.Location), nil
+}
+
+// ICalendarEventSource reads events from an iCalendar file.
+// Each VEVENT needs a calCMS event ID in X-CALCMS-EVENT-ID or a UID of the form calcms-event-<id>@...;
+// the series key is taken from X-CALCMS-SKEY or, if missing, from SUMMARY.
+type ICalendarEventSource struct {
+	Path     string
+	Location *time.Location
+}
+
+// QueryEvents returns the calendar entries with a calCMS event ID that start within the range.
+func (s *ICalendarEventSource) QueryEvents(ctx context.Context, from, till time.Time) ([]domain.CalCMSEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open iCalendar file: %w", err)
+	}
+	defer file.Close()
+	entries, err := ReadICalendar(file, s.Location)
+	if err != nil {
+		return nil, fmt.Errorf("read iCalendar file %q: %w", s.Path, err)
+	}
+	var events []domain.CalCMSEvent
+	for _, entry := range entries {
+		eventID := entry.EventID
+		if eventID == 0 {
+			eventID = eventIDFromUID(entry.UID)
+		}
+		if eventID == 0 {
+			continue
+		}
+		skey := entry.Skey
+		if skey == "" {
+			skey = entry.Summary
+		}
+		event := domain.CalCMSEvent{
+			EventID:       eventID,
+			Skey:          skey,
+			Title:         entry.Summary,
+			StartDateTime: entry.Start.In(s.Location).Format(eventDateTimeFormat),
+		}
+		if !entry.End.IsZero() {
+			event.EndDateTime = entry.End.In(s.Location).Format(eventDateTimeFormat)
+		}
+		events = append(events, event)
+	}
+	return eventsInRange(events, from, till, s.Location), nil
+}
+
+func eventIDFromUID(uid string) int {
+	rest, ok := strings.CutPrefix(uid, "calcms-event-")
+	if !ok {
+		return 0
+	}
+	id, _, _ := strings.Cut(rest, "@")
+	eventID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0
+	}
+	return eventID
+}
+
+// eventsInRange keeps the events starting at or after from and before till.
+// Events without a readable start time are dropped, because they cannot be placed in the range.
+func eventsInRange(events []domain.CalCMSEvent, from, till time.Time, loc *time.Location) []domain.CalCMSEvent {
+	var selected []domain.CalCMSEvent
+	for _, event := range events {
+		start, err := event.Start(loc)
+		if err != nil || start.Before(from) || !start.Before(till) {
+			continue
+		}
+		selected = append(selected, event)
+	}
+	return selected
+}
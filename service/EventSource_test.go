@@ -0,0 +1,121 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+)
+
+func writeEventSourceFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func eventIDs(t *testing.T, source EventSource, start, end time.Time) []int {
+	t.Helper()
+	events, err := source.QueryEvents(t.Context(), start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, event := range events {
+		ids = append(ids, event.EventID)
+	}
+	return ids
+}
+
+func TestJSONFileEventSourceFiltersByStart(t *testing.T) {
+	path := writeEventSourceFile(t, "events.json", `{"events":[
+		{"event_id":1,"skey":"show","start_datetime":"2026-07-20T23:59:00"},
+		{"event_id":2,"skey":"show","start_datetime":"2026-07-21T00:00:00"},
+		{"event_id":3,"skey":"show","start_datetime":"2026-07-27T23:59:00"},
+		{"event_id":4,"skey":"show","start_datetime":"2026-07-28T00:00:00"},
+		{"event_id":5,"skey":"show"}
+	]}`)
+	source := &JSONFileEventSource{Path: path, Location: time.UTC}
+	got := eventIDs(t, source, time.Date(2026, time.July, 21, 0, 0, 0, 0, time.UTC), time.Date(2026, time.July, 28, 0, 0, 0, 0, time.UTC))
+	if len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("event IDs = %v, want [2 3]", got)
+	}
+}
+
+func TestICalendarEventSource(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeEventSourceFile(t, "plan.ics", strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:calcms-event-42@calcmsfeeder",
+		"DTSTART:20260721T060000Z",
+		"DTEND:20260721T070000Z",
+		"SUMMARY:Morning\\, live",
+		"X-CALCMS-SKEY:Morgen",
+		" magazin",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:external-1",
+		"X-CALCMS-EVENT-ID:43",
+		"DTSTART;TZID=Europe/Berlin:20260722T080000",
+		"SUMMARY:Radio Zett!",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:no-calcms-id",
+		"DTSTART:20260723T080000",
+		"SUMMARY:Meeting",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n"))
+	source := &ICalendarEventSource{Path: path, Location: berlin}
+	events, err := source.QueryEvents(t.Context(), time.Date(2026, time.July, 21, 0, 0, 0, 0, berlin), time.Date(2026, time.July, 28, 0, 0, 0, 0, berlin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want 2 events with calCMS IDs", events)
+	}
+	first, second := events[0], events[1]
+	if first.EventID != 42 || first.Skey != "Morgenmagazin" || first.Title != "Morning, live" || first.StartDateTime != "2026-07-21T08:00:00" || first.EndDateTime != "2026-07-21T09:00:00" {
+		t.Fatalf("first event = %+v", first)
+	}
+	if second.EventID != 43 || second.Skey != "Radio Zett!" || second.StartDateTime != "2026-07-22T08:00:00" {
+		t.Fatalf("second event = %+v", second)
+	}
+}
+
+func TestNewEventSource(t *testing.T) {
+	cfg := serviceTestConfig("https://calendar.example")
+	calCms := NewCalCmsService(cfg)
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: config.EventSourceCalCms, want: "*service.DefaultCalCmsService"},
+		{source: config.EventSourceJSON, want: "*service.JSONFileEventSource"},
+		{source: config.EventSourceICalendar, want: "*service.ICalendarEventSource"},
+	}
+	for _, tt := range tests {
+		cfg.Events.Source = tt.source
+		got, err := NewEventSource(cfg, calCms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typeName := fmt.Sprintf("%T", got); typeName != tt.want {
+			t.Fatalf("source %q = %s, want %s", tt.source, typeName, tt.want)
+		}
+	}
+	cfg.Events.Source = "ftp"
+	if _, err := NewEventSource(cfg, calCms); err == nil {
+		t.Fatal("NewEventSource() accepted an unknown source")
+	}
+}
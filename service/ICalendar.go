@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icalDateTime   = "20060102T150405Z"
+	icalLineLength = 75
+)
+
+// ICalendarEvent is one VEVENT of an iCalendar file.
+type ICalendarEvent struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	// EventID and Skey are written as X-CALCMS-EVENT-ID and X-CALCMS-SKEY when set.
+	EventID int
+	Skey    string
+}
+
+// WriteICalendar writes events as an RFC 5545 calendar with UTC times.
+func WriteICalendar(w io.Writer, events []ICalendarEvent, stamp time.Time) error {
+	out := bufio.NewWriter(w)
+	writeICalendarLine(out, "BEGIN:VCALENDAR")
+	writeICalendarLine(out, "VERSION:2.0")
+	writeICalendarLine(out, "PRODID:-//calcmsfeeder//calCMS upload plan//EN")
+	writeICalendarLine(out, "CALSCALE:GREGORIAN")
+	for _, event := range events {
+		writeICalendarLine(out, "BEGIN:VEVENT")
+		writeICalendarLine(out, "UID:"+escapeICalendarText(event.UID))
+		writeICalendarLine(out, "DTSTAMP:"+stamp.UTC().Format(icalDateTime))
+		writeICalendarLine(out, "DTSTART:"+event.Start.UTC().Format(icalDateTime))
+		if !event.End.IsZero() {
+			writeICalendarLine(out, "DTEND:"+event.End.UTC().Format(icalDateTime))
+		}
+		writeICalendarLine(out, "SUMMARY:"+escapeICalendarText(event.Summary))
+		if event.Description != "" {
+			writeICalendarLine(out, "DESCRIPTION:"+escapeICalendarText(event.Description))
+		}
+		if event.EventID != 0 {
+			writeICalendarLine(out, "X-CALCMS-EVENT-ID:"+strconv.Itoa(event.EventID))
+		}
+		if event.Skey != "" {
+			writeICalendarLine(out, "X-CALCMS-SKEY:"+escapeICalendarText(event.Skey))
+		}
+		writeICalendarLine(out, "END:VEVENT")
+	}
+	writeICalendarLine(out, "END:VCALENDAR")
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write iCalendar: %w", err)
+	}
+	return nil
+}
+
+// writeICalendarLine folds content lines longer than 75 octets without splitting UTF-8 characters.
+func writeICalendarLine(w *bufio.Writer, line string) {
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalLineLength - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+var (
+	icalTextEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	icalTextUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func escapeICalendarText(text string) string {
+	return icalTextEscaper.Replace(text)
+}
+
+// ReadICalendar parses the VEVENTs of an iCalendar file.
+// Floating times and all-day dates are interpreted in loc; TZID parameters are honoured when the zone is known.
+func ReadICalendar(r io.Reader, loc *time.Location) ([]ICalendarEvent, error) {
+	lines, err := unfoldICalendarLines(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []ICalendarEvent
+	var current *ICalendarEvent
+	for number, line := range lines {
+		name, params, value, ok := splitICalendarLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &ICalendarEvent{}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN", number+1)
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: VEVENT %q has no DTSTART", number+1, current.UID)
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.UID = icalTextUnescaper.Replace(value)
+		case name == "SUMMARY":
+			current.Summary = icalTextUnescaper.Replace(value)
+		case name == "DESCRIPTION":
+			current.Description = icalTextUnescaper.Replace(value)
+		case name == "X-CALCMS-SKEY":
+			current.Skey = icalTextUnescaper.Replace(value)
+		case name == "X-CALCMS-EVENT-ID":
+			if current.EventID, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("line %d: invalid X-CALCMS-EVENT-ID %q", number+1, value)
+			}
+		case name == "DTSTART" || name == "DTEND":
+			t, err := parseICalendarTime(value, params, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number+1, err)
+			}
+			if name == "DTSTART" {
+				current.Start = t
+			} else {
+				current.End = t
+			}
+		}
+	}
+	return events, nil
+}
+
+func unfoldICalendarLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitICalendarLine splits "NAME;PARAM=x:value" into its upper-case name, parameters and value.
+func splitICalendarLine(line string) (string, map[string]string, string, bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", nil, "", false
+	}
+	parts := strings.Split(head, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		key, paramValue, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(paramValue, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, value, true
+}
+
+func parseICalendarTime(value string, params map[string]string, loc *time.Location) (time.Time, error) {
+	if tzid := params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == len("20060102"):
+		return time.ParseInLocation("20060102", value, loc)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse(icalDateTime, value)
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid iCalendar time %q", value)
+	}
+	return t, nil
+}
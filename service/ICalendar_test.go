@@ -0,0 +1,48 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICalendarEscapesAndFolds(t *testing.T) {
+	start := time.Date(2026, time.July, 21, 8, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	var out bytes.Buffer
+	err := WriteICalendar(&out, []ICalendarEvent{{
+		UID:         "calcms-event-42@calcmsfeeder",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Summary:     "Radio Zett!; Magazin, live",
+		Description: "Series: Radio Zett!\nUpload file: " + strings.Repeat("ü", 40),
+	}}, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20260721T060000Z\r\n",
+		"DTEND:20260721T070000Z\r\n",
+		`SUMMARY:Radio Zett!\; Magazin\, live` + "\r\n",
+		`DESCRIPTION:Series: Radio Zett!\nUpload file: `,
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("calendar does not contain %q:\n%s", want, got)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line longer than 75 octets: %q", line)
+		}
+		if !strings.HasPrefix(line, " ") && strings.ContainsRune(line, '\uFFFD') {
+			t.Fatalf("line splits a UTF-8 character: %q", line)
+		}
+	}
+	unfolded := strings.ReplaceAll(got, "\r\n ", "")
+	if !strings.Contains(unfolded, strings.Repeat("ü", 40)) {
+		t.Fatal("folded description does not unfold to the original text")
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	minRateLimitChunk = 1 << 10
+	maxRateLimitChunk = 32 << 10
+)
+
+// rateLimiter is a token bucket shared by all uploads of a service.
+// Concurrent writers reserve bytes in turn, so together they never exceed the rate.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// newRateLimiter returns a limiter for bytesPerSecond, or nil if the rate is unlimited.
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:  float64(bytesPerSecond),
+		burst: float64(rateLimitChunk(bytesPerSecond)),
+		now:   time.Now,
+		sleep: time.Sleep,
+	}
+}
+
+// rateLimitChunk keeps single writes small enough to pace slow rates smoothly.
+func rateLimitChunk(bytesPerSecond int64) int {
+	return int(min(max(bytesPerSecond/10, minRateLimitChunk), maxRateLimitChunk))
+}
+
+// wait blocks until n more bytes may be sent.
+func (l *rateLimiter) wait(n int) {
+	l.mu.Lock()
+	now := l.now()
+	if l.last.IsZero() {
+		l.tokens = l.burst
+	} else {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if delay > 0 {
+		l.sleep(delay)
+	}
+}
+
+// writer wraps w so that writes are paced by the limiter. A nil limiter returns w unchanged.
+func (l *rateLimiter) writer(w io.Writer) io.Writer {
+	if l == nil {
+		return w
+	}
+	return &rateLimitedWriter{limiter: l, writer: w, chunk: int(l.burst)}
+}
+
+type rateLimitedWriter struct {
+	limiter *rateLimiter
+	writer  io.Writer
+	chunk   int
+}
+
+func (w *rateLimitedWriter) Write(data []byte) (int, error) {
+	written := 0
+	for len(data) > 0 {
+		n := min(len(data), w.chunk)
+		w.limiter.wait(n)
+		n, err := w.writer.Write(data[:n])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		data = data[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPacesSharedWriters(t *testing.T) {
+	limiter := newRateLimiter(10 << 10)
+	now := time.Date(2026, time.July, 21, 12, 0, 0, 0, time.UTC)
+	var slept time.Duration
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+	var first, second bytes.Buffer
+	data := bytes.Repeat([]byte("x"), 10<<10)
+	if _, err := limiter.writer(&first).Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiter.writer(&second).Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if first.Len() != len(data) || second.Len() != len(data) {
+		t.Fatalf("written = %d and %d bytes, want %d each", first.Len(), second.Len(), len(data))
+	}
+	// 20 KiB at 10 KiB/s minus the initial 1 KiB burst.
+	if want := 1900 * time.Millisecond; slept != want {
+		t.Fatalf("slept %v, want %v", slept, want)
+	}
+}
+
+func TestUnlimitedRateLimiterKeepsWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	if w := newRateLimiter(0).writer(&buffer); w != &buffer {
+		t.Fatal("unlimited limiter wrapped the writer")
+	}
+}
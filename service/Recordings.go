@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	recordingRow  = regexp.MustCompile(`(?is)(<tr\b[^>]*>)(.*?)</tr>`)
+	recordingPath = regexp.MustCompile(`(?i)\bdata-path\s*=\s*["']([^"']+)["']`)
+)
+
+// Recording is one audio recording that calCMS lists for an event.
+type Recording struct {
+	Path   string
+	Active bool
+}
+
+// ActiveRecording returns the path of the active recording, or an empty string if none is active.
+func ActiveRecording(recordings []Recording) string {
+	for _, recording := range recordings {
+		if recording.Active {
+			return recording.Path
+		}
+	}
+	return ""
+}
+
+// Recordings lists the audio recordings calCMS holds for an event, active and inactive.
+func (s *DefaultCalCmsService) Recordings(ctx context.Context, eventID, seriesID int) ([]Recording, error) {
+	body, err := s.recordingsPage(ctx, eventID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	return parseRecordings(string(body)), nil
+}
+
+// parseRecordings reads the recording table rows. Only rows with a data-path attribute
+// are recordings; the cells may hold dates,
+// checkboxes or titles, so rows without the attribute are skipped rather than guessed at.
+func parseRecordings(page string) []Recording {
+	var recordings []Recording
+	for _, row := range recordingRow.FindAllStringSubmatch(page, -1) {
+		match := recordingPath.FindStringSubmatch(row[0])
+		if match == nil {
+			continue
+		}
+		path := html.UnescapeString(match[1])
+		if path == "" {
+			continue
+		}
+		recordings = append(recordings, Recording{Path: path, Active: activeRecordingRow.MatchString(row[1])})
+	}
+	return recordings
+}
+
+// DeleteRecording removes one recording of an event.
+func (s *DefaultCalCmsService) DeleteRecording(ctx context.Context, eventID, seriesID int, path string) error {
+	return s.recordingAction(ctx, "delete", eventID, seriesID, path)
+}
+
+// ActivateRecording makes one recording of an event the active one, for example the
+// recording that was active before an upload that is rolled back.
+func (s *DefaultCalCmsService) ActivateRecording(ctx context.Context, eventID, seriesID int, path string) error {
+	return s.recordingAction(ctx, "activate", eventID, seriesID, path)
+}
+
+// recordingAction posts action for the recording at path to the recordings page of an event.
+func (s *DefaultCalCmsService) recordingAction(ctx context.Context, action string, eventID, seriesID int, path string) error {
+	operation := "recording " + action
+	timeout := operationTimeout(s.Cfg, s.Cfg.CalCms.CheckTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	calURL, err := s.recordingsURL(eventID, seriesID)
+	if err != nil {
+		return err
+	}
+	calURL.RawQuery = ""
+	form := url.Values{}
+	form.Set("action", action)
+	form.Set("project_id", strconv.Itoa(s.Cfg.CalCms.ProjectID))
+	form.Set("studio_id", strconv.Itoa(s.Cfg.CalCms.StudioID))
+	form.Set("series_id", strconv.Itoa(seriesID))
+	form.Set("event_id", strconv.Itoa(eventID))
+	form.Set("path", path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, calURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("build %v request: %w", operation, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &RequestError{Operation: operation, Err: err, Timeout: timeout}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &HTTPStatusError{Operation: operation, StatusCode: resp.StatusCode}
+	}
+	if resp.Request == nil || resp.Request.Method != http.MethodPost || !sameEndpoint(resp.Request.URL, calURL) {
+		return fmt.Errorf("%w: %v was redirected away from the recordings page", ErrSessionLost, operation)
+	}
+	body, err := readLimitedBody(resp.Body, s.maxResponseSize())
+	if err != nil {
+		return fmt.Errorf("read %v response: %w", operation, err)
+	}
+	if message, ok := serverErrorMessage(body); ok {
+		return &RejectedError{Operation: fmt.Sprintf("%v of %q", operation, path), Message: message}
+	}
+	return nil
+}
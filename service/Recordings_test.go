@@ -0,0 +1,79 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseRecordings(t *testing.T) {
+	page := `<table>
+		<tr><th>File</th><th>Size</th></tr>
+		<tr class="inactive" data-path="2026-07-01_old.mp3"><td>2026-07-01</td><td>12 MB</td></tr>
+		<tr class="active recording" data-path="2026-07-20_new&amp;live.mp3"><td><a href="#">New &amp; live</a></td><td>14 MB</td></tr>
+		<tr class="active"><td>2026-07-21</td><td>without path</td></tr>
+		<tr data-path="nested/path.mp3"><td>path.mp3</td></tr>
+	</table>`
+	got := parseRecordings(page)
+	want := []Recording{{Path: "2026-07-01_old.mp3"}, {Path: "2026-07-20_new&live.mp3", Active: true}, {Path: "nested/path.mp3"}}
+	if len(got) != len(want) {
+		t.Fatalf("recordings = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("recording %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if active := ActiveRecording(got); active != "2026-07-20_new&live.mp3" {
+		t.Fatalf("ActiveRecording() = %q", active)
+	}
+}
+
+func TestRecordingActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  string
+		body    string
+		wantErr string
+	}{
+		{name: "deleted", action: "delete", body: `<html>ok</html>`},
+		{name: "delete rejected", action: "delete", body: `<div class="error" id="message">file not found</div>`, wantErr: "file not found"},
+		{name: "activated", action: "activate", body: `<html>ok</html>`},
+		{name: "activate rejected", action: "activate", body: `<div class="error" id="message">file not found</div>`, wantErr: "recording activate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/agenda/planung/audio-recordings.cgi" {
+					t.Errorf("request = %s %s", r.Method, r.URL.Path)
+				}
+				if err := r.ParseForm(); err != nil {
+					t.Error(err)
+				}
+				want := map[string]string{"action": tt.action, "project_id": "3", "studio_id": "4", "series_id": "99", "event_id": "42", "path": "upload.mp3"}
+				for key, value := range want {
+					if got := r.PostForm.Get(key); got != value {
+						t.Errorf("form %s = %q, want %q", key, got, value)
+					}
+				}
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+			svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
+			var err error
+			if tt.action == "delete" {
+				err = svc.DeleteRecording(t.Context(), 42, 99, "upload.mp3")
+			} else {
+				err = svc.ActivateRecording(t.Context(), 42, 99, "upload.mp3")
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("%v error = %v, want %q", tt.action, err, tt.wantErr)
+			}
+		})
+	}
+}
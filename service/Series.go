@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// ErrSeriesNotFound means calCMS has no series with the requested ID in the configured project and studio.
+var ErrSeriesNotFound = errors.New("series not found")
+
+var (
+	seriesNameInput = regexp.MustCompile(`(?is)<input\b[^>]*\bname\s*=\s*["']series_name["'][^>]*>`)
+	valueAttribute  = regexp.MustCompile(`(?is)\bvalue\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// SeriesName returns the name of a series from its calCMS planning page.
+// A page with an error message or without a series name counts as a missing series.
+func (s *DefaultCalCmsService) SeriesName(ctx context.Context, seriesID int) (string, error) {
+	// Series page: https://programm.coloradio.org/agenda/planung/series.cgi?project_id=1&studio_id=1&series_id=395&action=show_series
+	timeout := operationTimeout(s.Cfg, s.Cfg.CalCms.CheckTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	calURL, err := url.Parse(s.Cfg.CalCms.CmsHost)
+	if err != nil {
+		return "", fmt.Errorf("parse calCMS URL: %w", err)
+	}
+	calURL = calURL.JoinPath("agenda/planung/series.cgi")
+	query := url.Values{}
+	query.Set("project_id", strconv.Itoa(s.Cfg.CalCms.ProjectID))
+	query.Set("studio_id", strconv.Itoa(s.Cfg.CalCms.StudioID))
+	query.Set("series_id", strconv.Itoa(seriesID))
+	query.Set("action", "show_series")
+	calURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, calURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("build series request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", &RequestError{Operation: "series lookup", Err: err, Timeout: timeout}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", &HTTPStatusError{Operation: "series lookup", StatusCode: resp.StatusCode}
+	}
+	if resp.Request != nil && !sameEndpoint(resp.Request.URL, calURL) {
+		return "", fmt.Errorf("%w: series lookup was redirected to %q", ErrSessionLost, resp.Request.URL.Path)
+	}
+	body, err := readLimitedBody(resp.Body, s.maxResponseSize())
+	if err != nil {
+		return "", fmt.Errorf("read series response: %w", err)
+	}
+	if message, ok := serverErrorMessage(body); ok {
+		return "", fmt.Errorf("%w: %v", ErrSeriesNotFound, message)
+	}
+	name, ok := parseSeriesName(body)
+	if !ok {
+		return "", fmt.Errorf("%w: the series page shows no series name", ErrSeriesNotFound)
+	}
+	return name, nil
+}
+
+// parseSeriesName reads the value of the series name field of a series page.
+func parseSeriesName(body []byte) (string, bool) {
+	input := seriesNameInput.Find(body)
+	if input == nil {
+		return "", false
+	}
+	match := valueAttribute.FindSubmatch(input)
+	if match == nil {
+		return "", false
+	}
+	name := string(match[1]) + string(match[2])
+	if name == "" {
+		return "", false
+	}
+	return html.UnescapeString(name), true
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSeriesName(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		want     string
+		notFound bool
+	}{
+		{name: "found", body: `<form><input type="text" value="Radio Zett&amp;Co" name="series_name"></form>`, want: "Radio Zett&Co"},
+		{name: "error message", body: `<div class="error" id="message">series not found</div>`, notFound: true},
+		{name: "no series name", body: `<html>overview</html>`, notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if r.URL.Path != "/agenda/planung/series.cgi" || query.Get("series_id") != "99" || query.Get("project_id") != "3" || query.Get("studio_id") != "4" {
+					t.Errorf("request = %v", r.URL)
+				}
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+			svc := NewCalCmsServiceWithClient(serviceTestConfig(server.URL), server.Client())
+			got, err := svc.SeriesName(t.Context(), 99)
+			if tt.notFound {
+				if !errors.Is(err, ErrSeriesNotFound) {
+					t.Fatalf("SeriesName() error = %v, want ErrSeriesNotFound", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("SeriesName() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+)
+
+// defaultRequestTimeout applies to configurations without CALCMS_REQUEST_TIMEOUT.
+const defaultRequestTimeout = 5 * time.Minute
+
+// operationTimeout returns the timeout of one kind of request. Zero falls back to
+// CALCMS_REQUEST_TIMEOUT.
+func operationTimeout(cfg *config.AppConfig, configured time.Duration) time.Duration {
+	if configured > 0 {
+		return configured
+	}
+	if cfg.CalCms.RequestTimeout > 0 {
+		return cfg.CalCms.RequestTimeout
+	}
+	return defaultRequestTimeout
+}
+
+// uploadTimeout allows CALCMS_UPLOAD_TIMEOUT plus the time size bytes take at
+// CALCMS_UPLOAD_MIN_RATE, or at CALCMS_UPLOAD_RATE if that limit is lower, so a rate-limited
+// upload of a large file does not run into the timeout.
+func uploadTimeout(cfg *config.AppConfig, size int64) time.Duration {
+	timeout := operationTimeout(cfg, cfg.CalCms.UploadTimeout)
+	rate := int64(cfg.Uploads.MinRate)
+	if limit := int64(cfg.Uploads.Rate); limit > 0 && (rate <= 0 || limit < rate) {
+		rate = limit
+	}
+	if rate > 0 && size > 0 {
+		timeout += time.Duration(float64(size) / float64(rate) * float64(time.Second))
+	}
+	return timeout
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/johannes-kuhfuss/calcmsfeeder/config"
+)
+
+// newHTTPClient returns the client for calCMS requests. It has no overall timeout because
+// every request is limited by the timeout of its operation; CALCMS_CONNECT_TIMEOUT limits
+// connecting, including the TLS handshake. The proxy and TLS settings of the configuration
+// are applied to the transport; without CALCMS_PROXY the usual proxy environment variables apply.
+func newHTTPClient(cfg *config.AppConfig) *http.Client {
+	connectTimeout := operationTimeout(cfg, cfg.CalCms.ConnectTimeout)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	if cfg.Transport.ProxyURL != nil {
+		transport.Proxy = http.ProxyURL(cfg.Transport.ProxyURL)
+	}
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		RootCAs:      cfg.Transport.RootCAs,
+		Certificates: cfg.Transport.Certificates,
+	}
+	if len(cfg.Transport.PinnedSHA256) > 0 {
+		transport.TLSClientConfig.VerifyConnection = verifyPinnedCertificate(calCmsServerName(cfg), cfg.Transport.PinnedSHA256)
+	}
+	return &http.Client{Transport: transport}
+}
+
+// calCmsServerName returns the name the TLS handshake with calCMS reports as server name:
+// the host name of CALCMS_HOST, or nothing when calCMS is addressed by IP.
+func calCmsServerName(cfg *config.AppConfig) string {
+	host, err := url.Parse(cfg.CalCms.CmsHost)
+	if err != nil || net.ParseIP(host.Hostname()) != nil {
+		return ""
+	}
+	return strings.TrimSuffix(host.Hostname(), ".")
+}
+
+// verifyPinnedCertificate checks the SHA-256 fingerprint of the certificate calCMS presents,
+// in addition to the usual verification. Connections to other hosts, such as an HTTPS proxy,
+// are not pinned.
+func verifyPinnedCertificate(serverName string, pin []byte) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if state.ServerName != serverName {
+			return nil
+		}
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("calCMS presented no certificate")
+		}
+		fingerprint := sha256.Sum256(state.PeerCertificates[0].Raw)
+		if !bytes.Equal(fingerprint[:], pin) {
+			return fmt.Errorf("calCMS certificate fingerprint %X does not match CALCMS_PINNED_CERT_SHA256", fingerprint)
+		}
+		return nil
+	}
+}
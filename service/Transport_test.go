@@ -0,0 +1,130 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const emptyEventsResponse = `{"events":[]}`
+
+func queryTransportTestServer(t *testing.T, svc CalCmsService) error {
+	t.Helper()
+	_, err := svc.QueryEvents(t.Context(), time.Now(), time.Now().Add(time.Hour))
+	return err
+}
+
+func serverRootCAs(server *httptest.Server) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return pool
+}
+
+func TestCustomCertificateAuthority(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, emptyEventsResponse)
+	}))
+	defer server.Close()
+	cfg := serviceTestConfig(server.URL)
+	if err := queryTransportTestServer(t, NewCalCmsService(cfg)); err == nil {
+		t.Fatal("QueryEvents() trusted a certificate outside the system pool")
+	}
+	cfg.Transport.RootCAs = serverRootCAs(server)
+	if err := queryTransportTestServer(t, NewCalCmsService(cfg)); err != nil {
+		t.Fatalf("QueryEvents() error = %v", err)
+	}
+}
+
+func TestPinnedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, emptyEventsResponse)
+	}))
+	defer server.Close()
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+	cfg := serviceTestConfig(server.URL)
+	cfg.Transport.RootCAs = serverRootCAs(server)
+	cfg.Transport.PinnedSHA256 = fingerprint[:]
+	if err := queryTransportTestServer(t, NewCalCmsService(cfg)); err != nil {
+		t.Fatalf("QueryEvents() with matching pin error = %v", err)
+	}
+	cfg.Transport.PinnedSHA256 = make([]byte, sha256.Size)
+	err := queryTransportTestServer(t, NewCalCmsService(cfg))
+	if err == nil || !strings.Contains(err.Error(), "does not match CALCMS_PINNED_CERT_SHA256") {
+		t.Fatalf("QueryEvents() with other pin error = %v, want fingerprint mismatch", err)
+	}
+}
+
+func TestClientCertificate(t *testing.T) {
+	clientCert := testClientCertificate(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) != 1 || r.TLS.PeerCertificates[0].Subject.CommonName != "calcmsfeeder" {
+			t.Errorf("client certificates = %v", r.TLS.PeerCertificates)
+		}
+		io.WriteString(w, emptyEventsResponse)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+	cfg := serviceTestConfig(server.URL)
+	cfg.Transport.RootCAs = serverRootCAs(server)
+	if err := queryTransportTestServer(t, NewCalCmsService(cfg)); err == nil {
+		t.Fatal("QueryEvents() succeeded without client certificate")
+	}
+	cfg.Transport.Certificates = []tls.Certificate{clientCert}
+	if err := queryTransportTestServer(t, NewCalCmsService(cfg)); err != nil {
+		t.Fatalf("QueryEvents() error = %v", err)
+	}
+}
+
+func TestConfiguredProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		io.WriteString(w, emptyEventsResponse)
+	}))
+	defer proxy.Close()
+	cfg := serviceTestConfig("http://calendar.invalid")
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Transport.ProxyURL = proxyURL
+	if err := queryTransportTestServer(t, NewCalCmsService(cfg)); err != nil {
+		t.Fatalf("QueryEvents() error = %v", err)
+	}
+	if !strings.HasPrefix(proxied, "http://calendar.invalid/agenda/events.cgi?") {
+		t.Fatalf("proxied request = %q, want calCMS events URL", proxied)
+	}
+}
+
+func testClientCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "calcmsfeeder"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}